@@ -1,98 +1,405 @@
 package addressbook
 
 import (
-	"GoAddressBook/constants"
+	"GoAddressBook/fulltext"
 	"GoAddressBook/models"
+	"GoAddressBook/phonenumber"
+	"GoAddressBook/repository"
 	"GoAddressBook/utility"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/sagikazarmark/slog-shim"
-	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+)
+
+var (
+	ContactNotFound      = errors.New("contact not found in address book")
+	ContactAlreadyExists = errors.New("another contact already exists with one of the same phone numbers")
 )
 
 type AddressBook struct {
-	Contacts   map[string]models.Contact `json:"contacts,omitempty"`    // Using a map for quick lookups
-	NameIndex  map[string][]string       `json:"name_index,omitempty"`  // Index for name search
-	PhoneIndex map[string]string         `json:"phone_index,omitempty"` // Index for phone search
-	mutex      sync.RWMutex              // Mutex for concurrent access
+	Contacts      map[string]models.Contact `json:"contacts,omitempty"`    // Contacts by ID, using a map for quick lookups
+	NameIndex     map[string][]string       `json:"name_index,omitempty"`  // Index for name search, unused when the repository is a Finder
+	PhoneIndex    map[string]string         `json:"phone_index,omitempty"` // Index for phone search, covering every number of a contact, unused when the repository is a Finder
+	nameTrie      *nameTrie                 // Index of first and last names for prefix and fuzzy search
+	phoneticIndex phoneticIndex             // Optional index of names by how they sound
+	phoneRegion   string                    // Region of the phone numbers written without country code
+	textIndex     *fulltext.Index           // Inverted index of every field for full-text search
+	mutex         sync.RWMutex              // Mutex for concurrent access
+	repository    repository.Repository     // Storage the book is loaded from and saved to
+	finder        repository.Finder         // Repository answering name and phone lookups from its own indexes, if it does
 }
 
-// NewAddressBook creates a new AddressBook instance
-func NewAddressBook() *AddressBook {
-	return &AddressBook{
-		Contacts:   make(map[string]models.Contact),
-		NameIndex:  make(map[string][]string),
-		PhoneIndex: make(map[string]string),
-		mutex:      sync.RWMutex{},
+// NewAddressBook creates a new AddressBook instance persisted through the given repository.
+// When the repository is a Finder, names and phone numbers are looked up in its indexes instead of NameIndex and PhoneIndex.
+func NewAddressBook(repo repository.Repository) *AddressBook {
+	ab := &AddressBook{
+		Contacts:    make(map[string]models.Contact),
+		nameTrie:    newNameTrie(),
+		textIndex:   newTextIndex(),
+		phoneRegion: phonenumber.DefaultRegion,
+		mutex:       sync.RWMutex{},
+		repository:  repo,
 	}
+	ab.finder, _ = repo.(repository.Finder)
+	if ab.finder == nil {
+		ab.NameIndex = make(map[string][]string)
+		ab.PhoneIndex = make(map[string]string)
+	}
+	return ab
 }
 
-// LoadFromFile loads the address book from the JSON file
+// LoadFromFile loads the address book from its repository
 func (ab *AddressBook) LoadFromFile() error {
-	data, err := os.ReadFile(constants.AddressBookFilePath)
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	snapshot, err := ab.repository.Load()
 	if err != nil {
-		slog.Info("failed to read address book json file", err)
+		slog.Info("failed to load address book from repository", err)
 		return err
 	}
-	if err = json.Unmarshal(data, ab); err != nil {
-		slog.Info("failed to unmarshal contacts details", err)
+	ab.Contacts = snapshot.Contacts
+	if ab.Contacts == nil {
+		ab.Contacts = make(map[string]models.Contact)
+	}
+	if ab.finder == nil {
+		ab.NameIndex = snapshot.NameIndex
+		ab.PhoneIndex = snapshot.PhoneIndex
+		if ab.NameIndex == nil {
+			ab.NameIndex = make(map[string][]string)
+		}
+		if ab.PhoneIndex == nil {
+			ab.PhoneIndex = make(map[string]string)
+		}
+	}
+	ab.resetSearchIndexes()
+	for id, contact := range ab.Contacts {
+		ab.addToSearchIndexes(id, contact)
+	}
+
+	if replayer, ok := ab.repository.(repository.Replayer); ok {
+		if err = replayer.Replay(ab.replay); err != nil {
+			slog.Info("failed to replay address book changes", err)
+			return err
+		}
+	}
+	migrated := false
+	if ab.migrateKeys() {
+		slog.Info("migrated address book contacts to generated IDs", "contacts", len(ab.Contacts))
+		migrated = true
+	}
+	if normalized, collisions := ab.migratePhoneNumbers(); normalized {
+		slog.Info("normalized address book phone numbers to E.164", "contacts", len(ab.Contacts),
+			"collisions", len(collisions))
+		migrated = true
+	}
+	if migrated {
+		return ab.saveToFile()
+	}
+	ab.compactIfNeeded()
+	return nil
+}
+
+// migrateKeys moves contacts still stored under the legacy first-last-phone keys to generated IDs
+// and rebuilds the indexes accordingly. It reports whether anything was migrated.
+func (ab *AddressBook) migrateKeys() bool {
+	migrated := false
+	contacts := make(map[string]models.Contact, len(ab.Contacts))
+	for key, contact := range ab.Contacts {
+		if contact.ID == "" {
+			contact.ID = utility.NewContactID()
+		}
+		if contact.ID != key {
+			migrated = true
+		}
+		contacts[contact.ID] = contact
+	}
+	if !migrated {
+		return false
+	}
+	ab.Contacts = contacts
+	ab.rebuildIndexes()
+	return true
+}
+
+// rebuildIndexes recomputes the name, phone and search indexes from the contacts
+func (ab *AddressBook) rebuildIndexes() {
+	ids := make([]string, 0, len(ab.Contacts))
+	for id := range ab.Contacts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if ab.finder == nil {
+		ab.NameIndex = make(map[string][]string)
+		ab.PhoneIndex = make(map[string]string)
+	}
+	ab.resetSearchIndexes()
+	for _, id := range ids {
+		ab.addToIndexes(id, ab.Contacts[id])
+	}
+}
+
+// replay applies a recorded change to the book without persisting it again
+func (ab *AddressBook) replay(operation repository.Operation) error {
+	if existing, found := ab.Contacts[operation.Key]; found {
+		ab.removeFromIndexes(operation.Key, existing)
+	}
+
+	switch operation.Type {
+	case repository.AddOperation, repository.UpdateOperation:
+		ab.addToIndexes(operation.Key, operation.Contact)
+	case repository.DeleteOperation:
+	default:
+		return fmt.Errorf("unknown operation type %q", operation.Type)
+	}
+	return nil
+}
+
+// AddContact CreateContact add a new contact into the book under a newly generated ID, which is returned.
+// The book is left untouched when the contact can't be persisted.
+func (ab *AddressBook) AddContact(contact models.Contact) (string, error) {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	ab.normalizePhones(&contact)
+	for _, number := range contact.PhoneNumbers() {
+		_, taken, err := ab.phoneOwner(number)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", ContactAlreadyExists
+		}
+	}
+	contact.NormalizePrimary()
+	if contact.ID == "" {
+		contact.ID = utility.NewContactID()
+	}
+	if contact.ModifiedOn.IsZero() {
+		contact.ModifiedOn = time.Now()
+	}
+	if _, taken := ab.Contacts[contact.ID]; taken {
+		return "", ContactAlreadyExists
+	}
+	ab.addToIndexes(contact.ID, contact)
+
+	// Save to file
+	operation := repository.Operation{Type: repository.AddOperation, Key: contact.ID, Contact: contact, Time: time.Now()}
+	if err := ab.persist(operation); err != nil {
+		ab.removeFromIndexes(contact.ID, contact)
+		return "", err
+	}
+	return contact.ID, nil
+}
+
+// UpdateContact replaces the details of the contact identified by id, re-indexing it when the name or phone number changed.
+// The contact keeps its UID when none is given.
+func (ab *AddressBook) UpdateContact(id string, contact models.Contact) error {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	existing, found := ab.Contacts[id]
+	if !found {
+		return ContactNotFound
+	}
+	ab.normalizePhones(&contact)
+	for _, number := range contact.PhoneNumbers() {
+		owner, taken, err := ab.phoneOwner(number)
+		if err != nil {
+			return err
+		}
+		if taken && owner != id {
+			return ContactAlreadyExists
+		}
+	}
+	contact.NormalizePrimary()
+	contact.ID = id
+	if contact.UID == "" {
+		contact.UID = existing.UID
+	}
+	if contact.CreatedOn.IsZero() {
+		contact.CreatedOn = existing.CreatedOn
+	}
+	contact.ModifiedOn = time.Now()
+
+	ab.removeFromIndexes(id, existing)
+	ab.addToIndexes(id, contact)
+
+	// Save to file
+	operation := repository.Operation{Type: repository.UpdateOperation, Key: id, Contact: contact, Time: time.Now()}
+	if err := ab.persist(operation); err != nil {
+		ab.removeFromIndexes(id, contact)
+		ab.addToIndexes(id, existing)
 		return err
 	}
 	return nil
 }
 
-// AddContact CreateContact add a new contact into the book
-func (ab *AddressBook) AddContact(contact models.Contact) {
+// DeleteContact removes the contact identified by id along with its index entries
+func (ab *AddressBook) DeleteContact(id string) (models.Contact, error) {
 	ab.mutex.Lock()
 	defer ab.mutex.Unlock()
 
-	key := ab.generateKey(contact.FirstName, contact.LastName, contact.PhoneNumber)
+	contact, found := ab.Contacts[id]
+	if !found {
+		return models.Contact{}, ContactNotFound
+	}
+	ab.removeFromIndexes(id, contact)
+
+	// Save to file
+	operation := repository.Operation{Type: repository.DeleteOperation, Key: id, Contact: contact, Time: time.Now()}
+	if err := ab.persist(operation); err != nil {
+		ab.addToIndexes(id, contact)
+		return models.Contact{}, err
+	}
+	return contact, nil
+}
+
+// GetContact returns the contact identified by id
+func (ab *AddressBook) GetContact(id string) (models.Contact, bool) {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	contact, found := ab.Contacts[id]
+	return contact, found
+}
+
+// ContactsByKey returns a copy of every contact of the book mapped by its ID
+func (ab *AddressBook) ContactsByKey() map[string]models.Contact {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	contacts := make(map[string]models.Contact, len(ab.Contacts))
+	for key, contact := range ab.Contacts {
+		contacts[key] = contact
+	}
+	return contacts
+}
+
+// addToIndexes stores the contact under key and records it in the name and phone indexes
+func (ab *AddressBook) addToIndexes(key string, contact models.Contact) {
 	ab.Contacts[key] = contact
+	ab.addToSearchIndexes(key, contact)
+	if ab.finder != nil {
+		return
+	}
 
 	// Update name index
 	nameKey := ab.generateNameKey(contact.FirstName, contact.LastName)
 	ab.NameIndex[nameKey] = append(ab.NameIndex[nameKey], key)
 
 	// Update phone index
-	ab.PhoneIndex[contact.PhoneNumber] = key
-
-	// Save to file
-	ab.saveToFile()
+	for _, number := range contact.PhoneNumbers() {
+		ab.PhoneIndex[number] = key
+	}
 }
 
-// saveToFile saves the address book to the JSON file
-func (ab *AddressBook) saveToFile() {
-	err := os.Truncate(constants.AddressBookFilePath, 0)
-	if err != nil {
-		slog.Info("Failed to truncate address book file", err)
+// removeFromIndexes drops the contact stored under key and its entries in the name and phone indexes
+func (ab *AddressBook) removeFromIndexes(key string, contact models.Contact) {
+	delete(ab.Contacts, key)
+	ab.removeFromSearchIndexes(key, contact)
+	if ab.finder != nil {
 		return
 	}
 
-	file, _ := os.OpenFile(constants.AddressBookFilePath, os.O_RDWR|os.O_CREATE, os.ModePerm)
-	data, err := json.MarshalIndent(ab, "", "    ")
-	if err != nil {
-		slog.Info("failed to marshal contacts details", err)
+	nameKey := ab.generateNameKey(contact.FirstName, contact.LastName)
+	keys := ab.NameIndex[nameKey]
+	for i, indexedKey := range keys {
+		if indexedKey == key {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(keys) == 0 {
+		delete(ab.NameIndex, nameKey)
+	} else {
+		ab.NameIndex[nameKey] = keys
+	}
+
+	for _, number := range contact.PhoneNumbers() {
+		if ab.PhoneIndex[number] == key {
+			delete(ab.PhoneIndex, number)
+		}
+	}
+}
+
+// resetSearchIndexes empties the in-memory search indexes, which are never persisted
+func (ab *AddressBook) resetSearchIndexes() {
+	ab.nameTrie = newNameTrie()
+	ab.textIndex = newTextIndex()
+	if ab.phoneticIndex != nil {
+		ab.phoneticIndex = make(phoneticIndex)
+	}
+}
+
+// addToSearchIndexes records the contact in the name trie, phonetic and full-text indexes
+func (ab *AddressBook) addToSearchIndexes(key string, contact models.Contact) {
+	ab.nameTrie.add(key, contact)
+	ab.textIndex.Add(key, contactDocument(contact))
+	if ab.phoneticIndex != nil {
+		ab.phoneticIndex.add(key, contact)
+	}
+}
+
+// removeFromSearchIndexes drops the contact from the name trie, phonetic and full-text indexes
+func (ab *AddressBook) removeFromSearchIndexes(key string, contact models.Contact) {
+	ab.nameTrie.remove(key, contact)
+	ab.textIndex.Remove(key, contactDocument(contact))
+	if ab.phoneticIndex != nil {
+		ab.phoneticIndex.remove(key, contact)
+	}
+}
+
+// persist records a single change, rewriting the whole book only when the repository can't apply changes one by one
+func (ab *AddressBook) persist(operation repository.Operation) error {
+	journal, ok := ab.repository.(repository.Journal)
+	if !ok {
+		return ab.saveToFile()
+	}
+	if err := journal.Apply(operation); err != nil {
+		slog.Info("failed to apply change to address book", err)
+		return err
+	}
+	ab.compactIfNeeded()
+	return nil
+}
+
+// compactIfNeeded folds the recorded changes into a new snapshot once the repository asks for it.
+// The changes are already durable, so a failed compaction is only logged and retried on the next change.
+func (ab *AddressBook) compactIfNeeded() {
+	compactor, ok := ab.repository.(repository.Compactor)
+	if !ok || !compactor.NeedsCompaction() {
 		return
 	}
+	if err := ab.saveToFile(); err != nil {
+		slog.Info("failed to compact address book changes", err)
+	}
+}
 
-	_, err = file.Write(data)
+// saveToFile saves the address book through its repository
+func (ab *AddressBook) saveToFile() error {
+	err := ab.repository.Save(repository.Snapshot{
+		Contacts:   ab.Contacts,
+		NameIndex:  ab.NameIndex,
+		PhoneIndex: ab.PhoneIndex,
+	})
 	if err != nil {
-		slog.Info("Error writing to file:", err)
-		return
+		slog.Info("failed to save address book", err)
+		return err
 	}
-	err = file.Close()
-	return
+	return nil
 }
 
-// ListAllContacts returns a list of all the contacts in a pretty way
+// ListAllContacts returns a list of all the contacts in a pretty way, sorted by name
 func (ab *AddressBook) ListAllContacts() []string {
-	ab.mutex.RLock()
-	defer ab.mutex.RUnlock()
-
 	var contacts []string
-	for _, actualContact := range ab.Contacts {
+	for _, actualContact := range ab.AllContacts() {
 		actualContactByte, _ := json.Marshal(actualContact)
 		contacts = append(contacts, string(actualContactByte))
 	}
@@ -101,22 +408,28 @@ func (ab *AddressBook) ListAllContacts() []string {
 
 // SearchByName searches for a contact by name
 func (ab *AddressBook) SearchByName(name string) []models.Contact {
-	ab.mutex.RLock()
-	defer ab.mutex.RUnlock()
+	return ab.SearchByNameMode(name, ExactName)
+}
 
+// searchByExactName returns the contacts whose lowercase first and last names equal the searched ones
+func (ab *AddressBook) searchByExactName(name string) []models.Contact {
 	firstName, _, lastName := utility.GetFirstMiddleAndLastNamesFromFullName(name)
 	nameKey := ab.generateNameKey(firstName, lastName)
-	keys, found := ab.NameIndex[nameKey]
-	if !found {
-		return nil
+	keys := ab.NameIndex[nameKey]
+	if ab.finder != nil {
+		var err error
+		if keys, err = ab.finder.FindByName(nameKey); err != nil {
+			slog.Info("failed to search contacts by name in repository", err)
+			return nil
+		}
 	}
 
 	var results []models.Contact
 	for _, key := range keys {
-		contact, _ := ab.Contacts[key]
-		results = append(results, contact)
+		if contact, found := ab.Contacts[key]; found {
+			results = append(results, contact)
+		}
 	}
-
 	return results
 }
 
@@ -125,7 +438,11 @@ func (ab *AddressBook) SearchByPhoneNumber(phoneNumber string) (models.Contact,
 	ab.mutex.RLock()
 	defer ab.mutex.RUnlock()
 
-	key, found := ab.PhoneIndex[phoneNumber]
+	key, found, err := ab.phoneOwner(ab.normalizePhoneNumber(phoneNumber))
+	if err != nil {
+		slog.Info("failed to search contact by phone number in repository", err)
+		return models.Contact{}, false
+	}
 	if !found {
 		return models.Contact{}, false
 	}
@@ -134,10 +451,14 @@ func (ab *AddressBook) SearchByPhoneNumber(phoneNumber string) (models.Contact,
 	return contact, found
 }
 
-// generateKey generates a unique key for a contact based on first name, last name, and phone number
-func (ab *AddressBook) generateKey(firstName, lastName, phoneNumber string) string {
-
-	return fmt.Sprintf("%s-%s-%s", firstName, lastName, phoneNumber)
+// phoneOwner returns the ID of the contact owning the normalized phone number,
+// from the indexed column of the repository when it is a Finder
+func (ab *AddressBook) phoneOwner(number string) (string, bool, error) {
+	if ab.finder != nil {
+		return ab.finder.FindByPhoneNumber(number)
+	}
+	key, found := ab.PhoneIndex[number]
+	return key, found, nil
 }
 
 // generateNameKey generates a unique key for indexing names
@@ -0,0 +1,252 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	nameWeight    = 0.5
+	phoneWeight   = 0.2
+	emailWeight   = 0.2
+	addressWeight = 0.1
+
+	// DefaultDuplicateThreshold is the score from which two contacts are reported as duplicates:
+	// a close name plus a shared phone, email or address, or an identical phone and email.
+	DefaultDuplicateThreshold = 0.6
+)
+
+var CannotMergeContact = errors.New("a contact can't be merged with itself")
+
+// Duplicate is a pair of contacts likely to be the same person, with a score from 0 to 1
+type Duplicate struct {
+	First  models.Contact
+	Second models.Contact
+	Score  float64
+}
+
+// FindDuplicates scores every pair of contacts by the similarity of their names, phones, emails and addresses,
+// returning the pairs scoring at least the threshold, best first
+func (ab *AddressBook) FindDuplicates(threshold float64) []Duplicate {
+	ab.mutex.RLock()
+	contacts := make([]models.Contact, 0, len(ab.Contacts))
+	for _, contact := range ab.Contacts {
+		contacts = append(contacts, contact)
+	}
+	ab.mutex.RUnlock()
+
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].CreatedOn.Before(contacts[j].CreatedOn) ||
+			(contacts[i].CreatedOn.Equal(contacts[j].CreatedOn) && contacts[i].ID < contacts[j].ID)
+	})
+
+	var duplicates []Duplicate
+	for i := range contacts {
+		for j := i + 1; j < len(contacts); j++ {
+			if score := DuplicateScore(contacts[i], contacts[j]); score >= threshold {
+				duplicates = append(duplicates, Duplicate{First: contacts[i], Second: contacts[j], Score: score})
+			}
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Score > duplicates[j].Score
+	})
+	return duplicates
+}
+
+// DuplicateScore tells how likely two contacts are the same person, from 0 to 1
+func DuplicateScore(first, second models.Contact) float64 {
+	firstName, secondName := normalizedName(first.FirstName, first.LastName), normalizedName(second.FirstName, second.LastName)
+	nameScore := similarity(firstName, secondName)
+	if swapped := similarity(firstName, normalizedName(second.LastName, second.FirstName)); swapped > nameScore {
+		nameScore = swapped
+	}
+
+	phoneScore := 0.0
+	for _, number := range first.PhoneNumbers() {
+		for _, other := range second.PhoneNumbers() {
+			if number == other {
+				phoneScore = 1
+			}
+		}
+	}
+
+	emailScore := 0.0
+	for _, email := range first.Emails {
+		for _, other := range second.Emails {
+			address, otherAddress := strings.ToLower(email.Address), strings.ToLower(other.Address)
+			if address == otherAddress {
+				emailScore = 1
+			} else if local, _, _ := strings.Cut(address, "@"); emailScore < 0.5 && strings.HasPrefix(otherAddress, local+"@") {
+				emailScore = 0.5
+			}
+		}
+	}
+
+	addressScore := 0.0
+	for _, address := range first.Addresses {
+		for _, other := range second.Addresses {
+			if !strings.EqualFold(address.City, other.City) || address.City == "" {
+				continue
+			}
+			if address.Zip != "" && address.Zip == other.Zip {
+				addressScore = 1
+			} else if addressScore < 0.5 {
+				addressScore = 0.5
+			}
+		}
+	}
+
+	return nameWeight*nameScore + phoneWeight*phoneScore + emailWeight*emailScore + addressWeight*addressScore
+}
+
+// MergeContacts replaces the contact kept with the merged details and deletes the other one, both being re-indexed.
+// The merged contact keeps the ID of the first, its UID and the earliest creation date when none is given.
+func (ab *AddressBook) MergeContacts(keepID, removeID string, merged models.Contact) error {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	if keepID == removeID {
+		return CannotMergeContact
+	}
+	kept, found := ab.Contacts[keepID]
+	if !found {
+		return ContactNotFound
+	}
+	removed, found := ab.Contacts[removeID]
+	if !found {
+		return ContactNotFound
+	}
+	ab.normalizePhones(&merged)
+	for _, number := range merged.PhoneNumbers() {
+		owner, taken, err := ab.phoneOwner(number)
+		if err != nil {
+			return err
+		}
+		if taken && owner != keepID && owner != removeID {
+			return ContactAlreadyExists
+		}
+	}
+	merged.NormalizePrimary()
+	merged.ID = keepID
+	if merged.UID == "" {
+		merged.UID = kept.UID
+	}
+	if merged.CreatedOn.IsZero() {
+		merged.CreatedOn = kept.CreatedOn
+		if removed.CreatedOn.Before(kept.CreatedOn) {
+			merged.CreatedOn = removed.CreatedOn
+		}
+	}
+	merged.ModifiedOn = time.Now()
+
+	restoreIndexes := ab.saveIndexEntries(kept, removed, merged)
+	ab.removeFromIndexes(removeID, removed)
+	ab.removeFromIndexes(keepID, kept)
+	ab.addToIndexes(keepID, merged)
+	restore := func() {
+		ab.removeFromIndexes(keepID, merged)
+		ab.addToIndexes(keepID, kept)
+		ab.addToIndexes(removeID, removed)
+		restoreIndexes()
+	}
+
+	journal, ok := ab.repository.(repository.Journal)
+	if !ok {
+		if err := ab.saveToFile(); err != nil {
+			restore()
+			return err
+		}
+		return nil
+	}
+
+	// Both changes are recorded as one so the repository never holds the merged contact next to the removed one
+	now := time.Now()
+	err := journal.Apply(
+		repository.Operation{Type: repository.UpdateOperation, Key: keepID, Contact: merged, Time: now},
+		repository.Operation{Type: repository.DeleteOperation, Key: removeID, Contact: removed, Time: now},
+	)
+	if err != nil {
+		restore()
+		return err
+	}
+	ab.compactIfNeeded()
+	return nil
+}
+
+// saveIndexEntries records the name and phone index entries of the contacts, returning a function putting them back
+// exactly as they were
+func (ab *AddressBook) saveIndexEntries(contacts ...models.Contact) func() {
+	if ab.finder != nil {
+		return func() {}
+	}
+	names := make(map[string][]string)
+	owners := make(map[string]string) // empty when the number wasn't indexed
+	for _, contact := range contacts {
+		nameKey := ab.generateNameKey(contact.FirstName, contact.LastName)
+		names[nameKey] = append([]string(nil), ab.NameIndex[nameKey]...)
+		for _, number := range contact.PhoneNumbers() {
+			owners[number] = ab.PhoneIndex[number]
+		}
+	}
+	return func() {
+		for nameKey, keys := range names {
+			if len(keys) == 0 {
+				delete(ab.NameIndex, nameKey)
+			} else {
+				ab.NameIndex[nameKey] = keys
+			}
+		}
+		for number, owner := range owners {
+			if owner == "" {
+				delete(ab.PhoneIndex, number)
+			} else {
+				ab.PhoneIndex[number] = owner
+			}
+		}
+	}
+}
+
+// normalizedName lowercases the name and keeps only its letters and digits
+func normalizedName(firstName, lastName string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, firstName+lastName)
+}
+
+// similarity is 1 minus the edit distance between the strings relative to the longest one
+func similarity(first, second string) float64 {
+	a, b := []rune(first), []rune(second)
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return 1 - float64(previous[len(b)])/float64(longest)
+}
@@ -0,0 +1,136 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errJournal = errors.New("journal unavailable")
+
+// failingJournal is a memory repository whose change log always fails
+type failingJournal struct {
+	*repository.MemoryRepository
+	applied [][]repository.Operation
+}
+
+func (j *failingJournal) Apply(operations ...repository.Operation) error {
+	j.applied = append(j.applied, operations)
+	return errJournal
+}
+
+func TestMergeContactsFailureRestoresTheBook(t *testing.T) {
+	journal := &failingJournal{MemoryRepository: repository.NewMemoryRepository()}
+	err := journal.Save(repository.Snapshot{Contacts: map[string]models.Contact{
+		"a": {ID: "a", FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{
+			{Type: "mobile", Number: "+14155550100", Primary: true},
+		}},
+		"b": {ID: "b", FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{
+			{Type: "mobile", Number: "+14155550100", Primary: true},
+			{Type: "work", Number: "+14155550101"},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := NewAddressBook(journal)
+	if err = ab.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+	contacts := copyContacts(ab.Contacts)
+	nameIndex := copyNameIndex(ab.NameIndex)
+	phoneIndex := copyPhoneIndex(ab.PhoneIndex)
+
+	merged := models.Contact{FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{
+		{Type: "mobile", Number: "+14155550100", Primary: true},
+		{Type: "work", Number: "+14155550101"},
+		{Type: "home", Number: "+14155550102"},
+	}}
+	if err = ab.MergeContacts("b", "a", merged); !errors.Is(err, errJournal) {
+		t.Fatalf("MergeContacts error = %v, want %v", err, errJournal)
+	}
+
+	if len(journal.applied) != 1 || len(journal.applied[0]) != 2 {
+		t.Fatalf("journal got %d calls, want the update and delete in a single one", len(journal.applied))
+	}
+	if !reflect.DeepEqual(ab.Contacts, contacts) {
+		t.Errorf("contacts = %+v, want %+v", ab.Contacts, contacts)
+	}
+	if !reflect.DeepEqual(ab.NameIndex, nameIndex) {
+		t.Errorf("name index = %v, want %v", ab.NameIndex, nameIndex)
+	}
+	if !reflect.DeepEqual(ab.PhoneIndex, phoneIndex) {
+		t.Errorf("phone index = %v, want %v", ab.PhoneIndex, phoneIndex)
+	}
+}
+
+func TestMergeContactsRecordsOneChange(t *testing.T) {
+	dir := t.TempDir()
+	wal, err := repository.NewWalRepository(repository.NewJsonFileRepository(dir+"/book.json"), dir+"/book.wal", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	ab := NewAddressBook(wal)
+	if err = ab.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+	for _, contact := range []models.Contact{
+		{FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{{Type: "mobile", Number: "+14155550100", Primary: true}}},
+		{FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{{Type: "work", Number: "+14155550101", Primary: true}}},
+	} {
+		if _, err = ab.AddContact(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var ids []string
+	for id := range ab.Contacts {
+		ids = append(ids, id)
+	}
+	merged := models.Contact{FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{
+		{Type: "mobile", Number: "+14155550100", Primary: true},
+		{Type: "work", Number: "+14155550101"},
+	}}
+	if err = ab.MergeContacts(ids[0], ids[1], merged); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewAddressBook(wal)
+	if err = reloaded.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Contacts) != 1 {
+		t.Fatalf("reloaded %d contacts, want 1", len(reloaded.Contacts))
+	}
+	for _, number := range []string{"+14155550100", "+14155550101"} {
+		if owner := reloaded.PhoneIndex[number]; owner != ids[0] {
+			t.Errorf("%s indexed to %q, want %q", number, owner, ids[0])
+		}
+	}
+}
+
+func copyContacts(contacts map[string]models.Contact) map[string]models.Contact {
+	copied := make(map[string]models.Contact, len(contacts))
+	for key, contact := range contacts {
+		copied[key] = contact
+	}
+	return copied
+}
+
+func copyNameIndex(index map[string][]string) map[string][]string {
+	copied := make(map[string][]string, len(index))
+	for key, keys := range index {
+		copied[key] = append([]string(nil), keys...)
+	}
+	return copied
+}
+
+func copyPhoneIndex(index map[string]string) map[string]string {
+	copied := make(map[string]string, len(index))
+	for number, key := range index {
+		copied[number] = key
+	}
+	return copied
+}
@@ -0,0 +1,33 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"github.com/go-playground/validator/v10"
+)
+
+// ImportFailure tells why a contact of an imported file was rejected
+type ImportFailure struct {
+	Index   int // position of the contact in the file, from 1
+	Contact models.Contact
+	Err     error // a *utility.ValidationError when the contact is invalid
+}
+
+// Import validates and adds each contact, carrying on past the rejected ones.
+// It returns the number of contacts added and why the others were rejected.
+func (ab *AddressBook) Import(contacts []models.Contact, validate *validator.Validate) (int, []ImportFailure) {
+	added := 0
+	var failures []ImportFailure
+	for i, contact := range contacts {
+		err := utility.ValidateContact(validate, contact)
+		if err == nil {
+			_, err = ab.AddContact(contact)
+		}
+		if err != nil {
+			failures = append(failures, ImportFailure{Index: i + 1, Contact: contact, Err: err})
+			continue
+		}
+		added++
+	}
+	return added, failures
+}
@@ -0,0 +1,169 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	SortByName      = "name"
+	SortByCreatedOn = "created_on"
+	SortByCity      = "city"
+
+	DefaultPageSize = 20
+
+	// sortableTime formats dates with a fixed width so they order like strings
+	sortableTime = "2006-01-02T15:04:05.000000000Z"
+)
+
+var (
+	UnknownSortField = errors.New("unknown field to sort contacts by")
+	InvalidCursor    = errors.New("invalid or mismatched page cursor")
+)
+
+// SortFields lists the fields contacts can be listed by
+var SortFields = []string{SortByName, SortByCreatedOn, SortByCity}
+
+// ListOptions selects the order and the page of a listing
+type ListOptions struct {
+	SortBy     string // one of SortFields, by name when empty
+	Descending bool
+	Limit      int    // size of the page, DefaultPageSize when not positive
+	Cursor     string // NextCursor of the previous page, empty for the first one
+}
+
+// Page is one page of a listing
+type Page struct {
+	Contacts   []models.Contact
+	NextCursor string // empty on the last page
+}
+
+// cursor remembers the position of the last contact of a page, so pages stay consistent when contacts are added or deleted
+type cursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	Key        string `json:"k"`
+	ID         string `json:"i"`
+}
+
+type sortedContact struct {
+	key     string
+	contact models.Contact
+}
+
+// List returns a page of contacts sorted by the chosen field, the next pages being read by passing back its cursor
+func (ab *AddressBook) List(options ListOptions) (Page, error) {
+	if options.SortBy == "" {
+		options.SortBy = SortByName
+	}
+	if options.Limit <= 0 {
+		options.Limit = DefaultPageSize
+	}
+	if _, known := sortKey(options.SortBy, models.Contact{}); !known {
+		return Page{}, UnknownSortField
+	}
+
+	var after *cursor
+	if options.Cursor != "" {
+		decoded, err := decodeCursor(options.Cursor)
+		if err != nil || decoded.SortBy != options.SortBy || decoded.Descending != options.Descending {
+			return Page{}, InvalidCursor
+		}
+		after = &decoded
+	}
+
+	ab.mutex.RLock()
+	sorted := make([]sortedContact, 0, len(ab.Contacts))
+	for _, contact := range ab.Contacts {
+		key, _ := sortKey(options.SortBy, contact)
+		sorted = append(sorted, sortedContact{key: key, contact: contact})
+	}
+	ab.mutex.RUnlock()
+
+	less := func(leftKey, leftID, rightKey, rightID string) bool {
+		if leftKey == rightKey {
+			leftKey, rightKey = leftID, rightID
+		}
+		if options.Descending {
+			return leftKey > rightKey
+		}
+		return leftKey < rightKey
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i].key, sorted[i].contact.ID, sorted[j].key, sorted[j].contact.ID)
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(sorted), func(i int) bool {
+			return less(after.Key, after.ID, sorted[i].key, sorted[i].contact.ID)
+		})
+	}
+	end := start + options.Limit
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	page := Page{Contacts: make([]models.Contact, 0, end-start)}
+	for _, entry := range sorted[start:end] {
+		page.Contacts = append(page.Contacts, entry.contact)
+	}
+	if end < len(sorted) {
+		last := sorted[end-1]
+		page.NextCursor = encodeCursor(cursor{
+			SortBy:     options.SortBy,
+			Descending: options.Descending,
+			Key:        last.key,
+			ID:         last.contact.ID,
+		})
+	}
+	return page, nil
+}
+
+// AllContacts returns every contact of the book, sorted by name
+func (ab *AddressBook) AllContacts() []models.Contact {
+	var contacts []models.Contact
+	options := ListOptions{SortBy: SortByName}
+	for {
+		page, _ := ab.List(options)
+		contacts = append(contacts, page.Contacts...)
+		if page.NextCursor == "" {
+			return contacts
+		}
+		options.Cursor = page.NextCursor
+	}
+}
+
+// sortKey returns the string contacts are ordered by for the field, and false for an unknown field
+func sortKey(field string, contact models.Contact) (string, bool) {
+	name := strings.ToLower(contact.FirstName) + "\x00" + strings.ToLower(contact.LastName)
+	switch field {
+	case SortByName:
+		return name, true
+	case SortByCreatedOn:
+		return contact.CreatedOn.UTC().Format(sortableTime), true
+	case SortByCity:
+		return strings.ToLower(contact.PrimaryAddress().City) + "\x00" + name, true
+	default:
+		return "", false
+	}
+}
+
+func encodeCursor(position cursor) string {
+	encoded, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var position cursor
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return position, err
+	}
+	err = json.Unmarshal(decoded, &position)
+	return position, err
+}
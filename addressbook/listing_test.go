@@ -0,0 +1,110 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listingBook holds two namesakes and two contacts in the same city, so the ties of every order show
+func listingBook() *AddressBook {
+	ab := NewAddressBook(repository.NewMemoryRepository())
+	day := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, contact := range []models.Contact{
+		{ID: "1", FirstName: "Grace", LastName: "Hopper", Addresses: []models.Address{{City: "Arlington", Primary: true}}},
+		{ID: "2", FirstName: "Ada", LastName: "Lovelace", Addresses: []models.Address{{City: "London", Primary: true}}},
+		{ID: "3", FirstName: "Alan", LastName: "Turing", Addresses: []models.Address{{City: "London", Primary: true}}},
+		{ID: "4", FirstName: "Ada", LastName: "Lovelace"},
+		{ID: "5", FirstName: "Edsger", LastName: "Dijkstra", Addresses: []models.Address{{City: "Austin", Primary: true}}},
+	} {
+		contact.CreatedOn = day.AddDate(0, 0, -i)
+		ab.Contacts[contact.ID] = contact
+	}
+	return ab
+}
+
+// readPages follows the cursors from the first page to the last, returning the IDs of each page
+func readPages(t *testing.T, ab *AddressBook, options ListOptions) string {
+	t.Helper()
+	var pages []string
+	for {
+		page, err := ab.List(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, 0, len(page.Contacts))
+		for _, contact := range page.Contacts {
+			ids = append(ids, contact.ID)
+		}
+		pages = append(pages, strings.Join(ids, ","))
+		if page.NextCursor == "" {
+			return strings.Join(pages, " | ")
+		}
+		options.Cursor = page.NextCursor
+	}
+}
+
+func TestListByName(t *testing.T) {
+	ab := listingBook()
+	if got, want := readPages(t, ab, ListOptions{}), "2,4,3,5,1"; got != want {
+		t.Errorf("one page = %s, want %s", got, want)
+	}
+	if got, want := readPages(t, ab, ListOptions{Limit: 2}), "2,4 | 3,5 | 1"; got != want {
+		t.Errorf("pages of 2 = %s, want %s", got, want)
+	}
+	if got, want := readPages(t, ab, ListOptions{Limit: 2, Descending: true}), "1,5 | 3,4 | 2"; got != want {
+		t.Errorf("descending pages = %s, want %s", got, want)
+	}
+}
+
+func TestListByCreatedOn(t *testing.T) {
+	if got, want := readPages(t, listingBook(), ListOptions{SortBy: SortByCreatedOn, Limit: 3}), "5,4,3 | 2,1"; got != want {
+		t.Errorf("pages = %s, want %s", got, want)
+	}
+}
+
+func TestListByCity(t *testing.T) {
+	// Contacts without address come first, those of a city by name
+	if got, want := readPages(t, listingBook(), ListOptions{SortBy: SortByCity, Limit: 1}), "4 | 1 | 5 | 2 | 3"; got != want {
+		t.Errorf("pages = %s, want %s", got, want)
+	}
+}
+
+func TestListCursorSurvivesChanges(t *testing.T) {
+	ab := listingBook()
+	page, err := ab.List(ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last contact of the page goes away and one is added before the cursor
+	delete(ab.Contacts, "4")
+	ab.Contacts["6"] = models.Contact{ID: "6", FirstName: "Ada", LastName: "Byron"}
+
+	if got, want := readPages(t, ab, ListOptions{Limit: 2, Cursor: page.NextCursor}), "3,5 | 1"; got != want {
+		t.Errorf("next pages = %s, want %s", got, want)
+	}
+}
+
+func TestListRejectsMismatchedCursor(t *testing.T) {
+	ab := listingBook()
+	page, err := ab.List(ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, options := range []ListOptions{
+		{Cursor: "not a cursor"},
+		{SortBy: SortByCity, Cursor: page.NextCursor},
+		{Descending: true, Cursor: page.NextCursor},
+	} {
+		if _, err := ab.List(options); !errors.Is(err, InvalidCursor) {
+			t.Errorf("List(%+v) error = %v, want %v", options, err, InvalidCursor)
+		}
+	}
+	if _, err := ab.List(ListOptions{SortBy: "email"}); !errors.Is(err, UnknownSortField) {
+		t.Errorf("sorting by email error = %v, want %v", err, UnknownSortField)
+	}
+}
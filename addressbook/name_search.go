@@ -0,0 +1,272 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ExactMatch  = "exact"
+	PrefixMatch = "prefix"
+	FuzzyMatch  = "fuzzy"
+)
+
+// NameSearchOptions tunes a ranked name search
+type NameSearchOptions struct {
+	Prefix      bool // Match first or last names starting with the query words
+	MaxDistance int  // Edit distance tolerated per word, capped to a third of the word length
+	Limit       int  // Maximum number of results, 0 for no limit
+	Phonetic    bool // Also match names sounding like the query words, when the phonetic index is enabled
+}
+
+// NameMatch is a contact found by a ranked name search
+type NameMatch struct {
+	Contact models.Contact `json:"contact"`
+	Kind    string         `json:"kind"` // Weakest kind of match among the query words
+	Cost    int            `json:"cost"` // Lower is better, 0 being an exact match of every word
+}
+
+// SearchNames returns the contacts whose first or last name match every word of the query,
+// exactly, by prefix, within the tolerated edit distance or phonetically, best matches first
+func (ab *AddressBook) SearchNames(query string, options NameSearchOptions) []NameMatch {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches map[string]NameMatch
+	for i, word := range words {
+		costs := ab.nameTrie.search(word, options.Prefix, maxDistance(word, options.MaxDistance))
+		if options.Phonetic && ab.phoneticIndex != nil {
+			for id := range ab.phoneticIndex.match([]string{word}) {
+				if _, found := costs[id]; !found {
+					costs[id] = nameCost{kind: PhoneticMatch, cost: 3}
+				}
+			}
+		}
+		next := make(map[string]NameMatch, len(costs))
+		for id, cost := range costs {
+			match := NameMatch{Kind: cost.kind, Cost: cost.cost}
+			if i > 0 {
+				previous, found := matches[id]
+				if !found {
+					continue
+				}
+				match.Cost += previous.Cost
+				if weaker(previous.Kind, match.Kind) {
+					match.Kind = previous.Kind
+				}
+			}
+			next[id] = match
+		}
+		matches = next
+	}
+
+	results := make([]NameMatch, 0, len(matches))
+	for id, match := range matches {
+		match.Contact = ab.Contacts[id]
+		results = append(results, match)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Cost != results[j].Cost {
+			return results[i].Cost < results[j].Cost
+		}
+		left := ab.generateNameKey(results[i].Contact.FirstName, results[i].Contact.LastName)
+		right := ab.generateNameKey(results[j].Contact.FirstName, results[j].Contact.LastName)
+		if left != right {
+			return left < right
+		}
+		return results[i].Contact.ID < results[j].Contact.ID
+	})
+	if options.Limit > 0 && len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+	return results
+}
+
+// maxDistance caps the tolerated edit distance so short words don't match everything
+func maxDistance(word string, tolerated int) int {
+	limit := utf8.RuneCountInString(word) / 3
+	if tolerated < limit {
+		return tolerated
+	}
+	return limit
+}
+
+// weaker tells whether kind is a weaker match than other
+func weaker(kind, other string) bool {
+	rank := map[string]int{ExactMatch: 0, PrefixMatch: 1, FuzzyMatch: 2, PhoneticMatch: 3}
+	return rank[kind] > rank[other]
+}
+
+// nameCost is how well a contact matched one query word
+type nameCost struct {
+	kind string
+	cost int
+}
+
+// nameTrie indexes the lowercase first and last names of the contacts, character by character
+type nameTrie struct {
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	ids      map[string]int // Contacts owning the word ending on this node, counted when first and last names are equal
+}
+
+func newNameTrie() *nameTrie {
+	return &nameTrie{root: newTrieNode()}
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+// add indexes the first and last names of the contact
+func (t *nameTrie) add(id string, contact models.Contact) {
+	for _, word := range nameWords(contact) {
+		node := t.root
+		for _, r := range word {
+			child, found := node.children[r]
+			if !found {
+				child = newTrieNode()
+				node.children[r] = child
+			}
+			node = child
+		}
+		if node.ids == nil {
+			node.ids = make(map[string]int)
+		}
+		node.ids[id]++
+	}
+}
+
+// remove drops the first and last names of the contact, pruning the branches left empty
+func (t *nameTrie) remove(id string, contact models.Contact) {
+	for _, word := range nameWords(contact) {
+		t.root.remove(id, []rune(word))
+	}
+}
+
+// remove drops id from the word below the node and reports whether the node became empty
+func (n *trieNode) remove(id string, word []rune) bool {
+	if len(word) == 0 {
+		if n.ids[id] > 1 {
+			n.ids[id]--
+		} else {
+			delete(n.ids, id)
+		}
+	} else if child, found := n.children[word[0]]; found && child.remove(id, word[1:]) {
+		delete(n.children, word[0])
+	}
+	return len(n.ids) == 0 && len(n.children) == 0
+}
+
+// search returns the cost of the best match of the word for every contact:
+// 0 for an exact match, 1 for a prefix match and twice the edit distance for a fuzzy one
+func (t *nameTrie) search(word string, prefix bool, distance int) map[string]nameCost {
+	costs := make(map[string]nameCost)
+	keep := func(id string, cost nameCost) {
+		if current, found := costs[id]; !found || cost.cost < current.cost {
+			costs[id] = cost
+		}
+	}
+
+	query := []rune(word)
+	node := t.root
+	for _, r := range query {
+		node = node.children[r]
+		if node == nil {
+			break
+		}
+	}
+	if node != nil {
+		for id := range node.ids {
+			keep(id, nameCost{kind: ExactMatch, cost: 0})
+		}
+		if prefix {
+			node.walk(func(descendant *trieNode) {
+				if descendant == node {
+					return
+				}
+				for id := range descendant.ids {
+					keep(id, nameCost{kind: PrefixMatch, cost: 1})
+				}
+			})
+		}
+	}
+
+	if distance > 0 {
+		row := make([]int, len(query)+1)
+		for i := range row {
+			row[i] = i
+		}
+		for r, child := range t.root.children {
+			child.fuzzy(r, query, row, distance, keep)
+		}
+	}
+	return costs
+}
+
+// walk calls visit on the node and every node below it
+func (n *trieNode) walk(visit func(node *trieNode)) {
+	visit(n)
+	for _, child := range n.children {
+		child.walk(visit)
+	}
+}
+
+// fuzzy computes the Levenshtein distance row of the node from the row of its parent,
+// keeping the words within distance and only descending while a match is still possible
+func (n *trieNode) fuzzy(r rune, query []rune, previous []int, distance int, keep func(id string, cost nameCost)) {
+	row := make([]int, len(previous))
+	row[0] = previous[0] + 1
+	smallest := row[0]
+	for i := 1; i < len(row); i++ {
+		substitution := previous[i-1]
+		if query[i-1] != r {
+			substitution++
+		}
+		row[i] = minimum(row[i-1]+1, previous[i]+1, substitution)
+		if row[i] < smallest {
+			smallest = row[i]
+		}
+	}
+
+	if last := row[len(row)-1]; last > 0 && last <= distance {
+		for id := range n.ids {
+			keep(id, nameCost{kind: FuzzyMatch, cost: 2 * last})
+		}
+	}
+	if smallest > distance {
+		return
+	}
+	for next, child := range n.children {
+		child.fuzzy(next, query, row, distance, keep)
+	}
+}
+
+// nameWords returns the lowercase first and last names of the contact
+func nameWords(contact models.Contact) []string {
+	var words []string
+	for _, name := range []string{contact.FirstName, contact.LastName} {
+		words = append(words, strings.Fields(strings.ToLower(name))...)
+	}
+	return words
+}
+
+func minimum(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+	return smallest
+}
@@ -0,0 +1,101 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/phonenumber"
+	"GoAddressBook/utility"
+	"github.com/sagikazarmark/slog-shim"
+	"sort"
+)
+
+// SetPhoneRegion sets the region of the phone numbers written without country code, used to normalize them to E.164.
+// It must be called before loading the book, whose numbers are normalized when loaded.
+func (ab *AddressBook) SetPhoneRegion(region string) {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	ab.phoneRegion = region
+}
+
+// normalizePhoneNumber returns the number in E.164, or as typed without its formatting when it isn't a valid number
+func (ab *AddressBook) normalizePhoneNumber(number string) string {
+	normalized, err := phonenumber.Normalize(number, ab.phoneRegion)
+	if err != nil {
+		return utility.CleanPhoneNumber(number)
+	}
+	return normalized
+}
+
+// normalizePhones rewrites every phone number of the contact in E.164
+func (ab *AddressBook) normalizePhones(contact *models.Contact) {
+	phones := make([]models.Phone, len(contact.Phones))
+	for i, phone := range contact.Phones {
+		phones[i] = phone
+		phones[i].Number = ab.normalizePhoneNumber(phone.Number)
+	}
+	contact.Phones = phones
+}
+
+// PhoneCollision is a phone number two contacts share once normalized to E.164
+type PhoneCollision struct {
+	Number  string // normalized number
+	Owner   string // ID of the contact the number stays indexed to
+	Contact string // ID of the contact whose number was left as it was stored
+}
+
+// migratePhoneNumbers normalizes the numbers of the contacts saved before they were stored in E.164, re-indexing the book.
+// A number normalizing to one already owned by another contact is left as it was stored, so that the index entry of the
+// owner isn't overwritten, and is reported as a collision.
+func (ab *AddressBook) migratePhoneNumbers() (bool, []PhoneCollision) {
+	ids := make([]string, 0, len(ab.Contacts))
+	for id := range ab.Contacts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// Numbers already stored as they are keep their owners
+	owners := make(map[string]string)
+	for _, id := range ids {
+		for _, number := range ab.Contacts[id].PhoneNumbers() {
+			if _, taken := owners[number]; !taken {
+				owners[number] = id
+			}
+		}
+	}
+
+	migrated := false
+	var collisions []PhoneCollision
+	for _, id := range ids {
+		contact := ab.Contacts[id]
+		phones := make([]models.Phone, len(contact.Phones))
+		changed := false
+		for i, phone := range contact.Phones {
+			phones[i] = phone
+			normalized := ab.normalizePhoneNumber(phone.Number)
+			if normalized == phone.Number {
+				continue
+			}
+			if owner, taken := owners[normalized]; taken && owner != id {
+				slog.Info("phone number collision, left as stored", "number", normalized, "owner", owner,
+					"contact", id, "stored", phone.Number)
+				collisions = append(collisions, PhoneCollision{Number: normalized, Owner: owner, Contact: id})
+				continue
+			}
+			if owners[phone.Number] == id {
+				delete(owners, phone.Number)
+			}
+			owners[normalized] = id
+			phones[i].Number = normalized
+			changed = true
+		}
+		if changed {
+			contact.Phones = phones
+			ab.Contacts[id] = contact
+			migrated = true
+		}
+	}
+	if migrated {
+		ab.rebuildIndexes()
+	}
+	return migrated, collisions
+}
@@ -0,0 +1,92 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"testing"
+)
+
+func TestLoadReportsNormalizedPhoneCollisions(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	err := repo.Save(repository.Snapshot{Contacts: map[string]models.Contact{
+		"a": {ID: "a", FirstName: "Ada", LastName: "Lovelace", Phones: []models.Phone{{Type: "mobile", Number: "+14155552671", Primary: true}}},
+		"b": {ID: "b", FirstName: "Alan", LastName: "Turing", Phones: []models.Phone{{Type: "mobile", Number: "(415) 555-2671", Primary: true}}},
+		"c": {ID: "c", FirstName: "Grace", LastName: "Hopper", Phones: []models.Phone{{Type: "mobile", Number: "415.555.0100", Primary: true}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ab := NewAddressBook(repo)
+	ab.SetPhoneRegion("US")
+	if err = ab.LoadFromFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if owner := ab.PhoneIndex["+14155552671"]; owner != "a" {
+		t.Errorf("+14155552671 indexed to %q, want a", owner)
+	}
+	if number := ab.Contacts["b"].Phones[0].Number; number != "(415) 555-2671" {
+		t.Errorf("colliding number rewritten to %q", number)
+	}
+	if owner := ab.PhoneIndex["(415) 555-2671"]; owner != "b" {
+		t.Errorf("colliding number indexed to %q, want b", owner)
+	}
+	if number := ab.Contacts["c"].Phones[0].Number; number != "+14155550100" {
+		t.Errorf("number normalized to %q, want +14155550100", number)
+	}
+}
+
+func TestMigratePhoneNumbersCollisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		contacts   map[string]models.Contact
+		collisions []PhoneCollision
+	}{
+		{
+			name: "distinct numbers",
+			contacts: map[string]models.Contact{
+				"a": {Phones: []models.Phone{{Number: "415-555-2671"}}},
+				"b": {Phones: []models.Phone{{Number: "415-555-0100"}}},
+			},
+		},
+		{
+			name: "number already stored normalized keeps its owner",
+			contacts: map[string]models.Contact{
+				"a": {Phones: []models.Phone{{Number: "415-555-2671"}}},
+				"b": {Phones: []models.Phone{{Number: "+14155552671"}}},
+			},
+			collisions: []PhoneCollision{{Number: "+14155552671", Owner: "b", Contact: "a"}},
+		},
+		{
+			name: "first contact by ID keeps the number",
+			contacts: map[string]models.Contact{
+				"b": {Phones: []models.Phone{{Number: "(415) 555-2671"}}},
+				"a": {Phones: []models.Phone{{Number: "415.555.2671"}}},
+			},
+			collisions: []PhoneCollision{{Number: "+14155552671", Owner: "a", Contact: "b"}},
+		},
+		{
+			name: "same contact twice is no collision",
+			contacts: map[string]models.Contact{
+				"a": {Phones: []models.Phone{{Number: "415-555-2671"}, {Number: "(415) 555-2671"}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ab := NewAddressBook(repository.NewMemoryRepository())
+			ab.SetPhoneRegion("US")
+			ab.Contacts = test.contacts
+			_, collisions := ab.migratePhoneNumbers()
+			if len(collisions) != len(test.collisions) {
+				t.Fatalf("collisions = %+v, want %+v", collisions, test.collisions)
+			}
+			for i := range collisions {
+				if collisions[i] != test.collisions[i] {
+					t.Errorf("collision %d = %+v, want %+v", i, collisions[i], test.collisions[i])
+				}
+			}
+		})
+	}
+}
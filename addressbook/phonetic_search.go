@@ -0,0 +1,136 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/phonetic"
+	"GoAddressBook/utility"
+	"sort"
+	"strings"
+)
+
+const PhoneticMatch = "phonetic"
+
+// NameSearchMode selects how SearchByNameMode matches names
+type NameSearchMode int
+
+const (
+	ExactName    NameSearchMode = iota // Only contacts whose first and last names equal the searched ones
+	PhoneticName                       // Exact matches first, then contacts whose names sound alike
+)
+
+// phoneticIndex maps the phonetic keys of first and last names to the contacts owning them,
+// counted when several names of a contact share a key
+type phoneticIndex map[string]map[string]int
+
+// EnablePhoneticIndex turns on the phonetic index of names, maintained by every change of the book
+func (ab *AddressBook) EnablePhoneticIndex() {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	ab.phoneticIndex = make(phoneticIndex)
+	for id, contact := range ab.Contacts {
+		ab.phoneticIndex.add(id, contact)
+	}
+}
+
+// SearchByNameMode searches for contacts by name, the phonetic mode returning
+// contacts whose names sound alike ranked below the exact matches
+func (ab *AddressBook) SearchByNameMode(name string, mode NameSearchMode) []models.Contact {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	results := ab.searchByExactName(name)
+	if mode != PhoneticName || ab.phoneticIndex == nil {
+		return results
+	}
+
+	found := make(map[string]bool, len(results))
+	for _, contact := range results {
+		found[contact.ID] = true
+	}
+	firstName, _, lastName := utility.GetFirstMiddleAndLastNamesFromFullName(name)
+	var ids []string
+	for id := range ab.phoneticIndex.match([]string{firstName, lastName}) {
+		if !found[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		left, right := ab.Contacts[ids[i]], ab.Contacts[ids[j]]
+		leftKey := ab.generateNameKey(left.FirstName, left.LastName)
+		rightKey := ab.generateNameKey(right.FirstName, right.LastName)
+		if leftKey != rightKey {
+			return leftKey < rightKey
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		results = append(results, ab.Contacts[id])
+	}
+	return results
+}
+
+// add indexes the phonetic keys of the first and last names of the contact
+func (p phoneticIndex) add(id string, contact models.Contact) {
+	for _, word := range nameWords(contact) {
+		for _, key := range phoneticKeys(word) {
+			if p[key] == nil {
+				p[key] = make(map[string]int)
+			}
+			p[key][id]++
+		}
+	}
+}
+
+// remove drops the phonetic keys of the first and last names of the contact
+func (p phoneticIndex) remove(id string, contact models.Contact) {
+	for _, word := range nameWords(contact) {
+		for _, key := range phoneticKeys(word) {
+			if p[key][id] > 1 {
+				p[key][id]--
+				continue
+			}
+			delete(p[key], id)
+			if len(p[key]) == 0 {
+				delete(p, key)
+			}
+		}
+	}
+}
+
+// match returns the contacts having a name which sounds like each of the words
+func (p phoneticIndex) match(words []string) map[string]bool {
+	var matches map[string]bool
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		wordMatches := make(map[string]bool)
+		for _, key := range phoneticKeys(word) {
+			for id := range p[key] {
+				if matches == nil || matches[id] {
+					wordMatches[id] = true
+				}
+			}
+		}
+		matches = wordMatches
+	}
+	return matches
+}
+
+// phoneticKeys returns the distinct Double Metaphone and Indic keys of a word, prefixed by their algorithm
+func phoneticKeys(word string) []string {
+	primary, alternate := phonetic.DoubleMetaphone(word)
+	candidates := []string{"dm:" + primary, "dm:" + alternate, "in:" + phonetic.IndicKey(strings.ToLower(word))}
+
+	keys := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, key := range candidates {
+		if strings.HasSuffix(key, ":") || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,28 @@
+package addressbook
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/query"
+)
+
+// Query returns the contacts matching a structured query such as
+// `state = "karnataka" AND created_on > 2023-01-01 ORDER BY last_name LIMIT 20`
+func (ab *AddressBook) Query(text string) ([]models.Contact, error) {
+	parsed, err := query.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return ab.RunQuery(parsed), nil
+}
+
+// RunQuery evaluates an already parsed query against the contacts of the book
+func (ab *AddressBook) RunQuery(parsed *query.Query) []models.Contact {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	contacts := make([]models.Contact, 0, len(ab.Contacts))
+	for _, contact := range ab.Contacts {
+		contacts = append(contacts, contact)
+	}
+	return parsed.Run(contacts)
+}
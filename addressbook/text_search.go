@@ -0,0 +1,69 @@
+package addressbook
+
+import (
+	"GoAddressBook/fulltext"
+	"GoAddressBook/models"
+	"sort"
+)
+
+// textAliases lets full-text queries use a single field name for several indexed fields
+var textAliases = map[string][]string{
+	"name":    {"first_name", "last_name"},
+	"pincode": {"zip"},
+	"pin":     {"zip"},
+}
+
+// Search returns the contacts matching a full-text query over every field, such as
+// `yadav city:bangaluru` or `email:gmail OR state:"tamil nadu"`, sorted by name
+func (ab *AddressBook) Search(query string) ([]models.Contact, error) {
+	ab.mutex.RLock()
+	defer ab.mutex.RUnlock()
+
+	ids, err := ab.textIndex.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.Contact, 0, len(ids))
+	for id := range ids {
+		results = append(results, ab.Contacts[id])
+	}
+	sort.Slice(results, func(i, j int) bool {
+		left := ab.generateNameKey(results[i].FirstName, results[i].LastName)
+		right := ab.generateNameKey(results[j].FirstName, results[j].LastName)
+		if left != right {
+			return left < right
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+func newTextIndex() *fulltext.Index {
+	return fulltext.NewIndex(textAliases)
+}
+
+// contactDocument lists the values of every searchable field of the contact and its addresses
+func contactDocument(contact models.Contact) fulltext.Document {
+	document := fulltext.Document{
+		"first_name": {contact.FirstName},
+		"last_name":  {contact.LastName},
+	}
+	for _, phone := range contact.Phones {
+		document["phone"] = append(document["phone"], phone.Number)
+		document["phone_type"] = append(document["phone_type"], phone.Type)
+	}
+	for _, email := range contact.Emails {
+		document["email"] = append(document["email"], email.Address)
+		document["email_type"] = append(document["email_type"], email.Type)
+	}
+	for _, address := range contact.Addresses {
+		document["address_type"] = append(document["address_type"], address.Type)
+		document["street"] = append(document["street"], address.Street)
+		document["city"] = append(document["city"], address.City)
+		document["state"] = append(document["state"], address.State)
+		document["zip"] = append(document["zip"], address.Zip)
+		document["country"] = append(document["country"], address.Country)
+	}
+	return document
+}
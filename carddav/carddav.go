@@ -0,0 +1,345 @@
+// Package carddav serves the address book to the contact clients of phones and desktops over CardDAV (RFC 6352):
+//
+//	/.well-known/carddav           redirects to the principal
+//	/carddav/                      principal and home of the address book, PROPFIND
+//	/carddav/contacts/             the address book, PROPFIND and the addressbook-query, addressbook-multiget
+//	                               and sync-collection REPORTs
+//	/carddav/contacts/{id}.vcf     a contact as a vCard, GET, PUT and DELETE with ETags
+//
+// Cards are served as vCard 3.0, or 4.0 when the client accepts it. A card PUT under a new name is added with the
+// name as ID, its UID being kept apart.
+package carddav
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"strings"
+)
+
+const (
+	WellKnownPath   = "/.well-known/carddav"
+	PrincipalPath   = "/carddav/"
+	AddressBookPath = "/carddav/contacts/"
+
+	cardExtension = ".vcf"
+	// maxCardSize limits the size of the vCards sent to the server
+	maxCardSize = 1 << 20
+)
+
+// resourceKind is what a path of the endpoint names
+type resourceKind int
+
+const (
+	noResource resourceKind = iota
+	principalResource
+	addressBookResource
+	cardResource
+)
+
+// Handler serves CardDAV over an address book
+type Handler struct {
+	Book      *addressbook.AddressBook
+	Validator *validator.Validate
+	sync      *syncState
+}
+
+// NewHandler returns a Handler over the address book
+func NewHandler(book *addressbook.AddressBook) *Handler {
+	return &Handler{
+		Book:      book,
+		Validator: utility.NewValidator(),
+		sync:      newSyncState(),
+	}
+}
+
+// Register routes the paths of the endpoint to the handler
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.Handle(WellKnownPath, h)
+	mux.Handle(PrincipalPath, h)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == WellKnownPath {
+		http.Redirect(w, r, PrincipalPath, http.StatusMovedPermanently)
+		return
+	}
+	if r.Method == http.MethodOptions {
+		w.Header().Set("DAV", "1, 3, addressbook")
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		return
+	}
+
+	kind, id := resolve(r.URL.Path)
+	switch {
+	case kind == noResource:
+		http.NotFound(w, r)
+	case r.Method == "PROPFIND":
+		h.propfind(w, r, kind, id)
+	case r.Method == "REPORT" && kind == addressBookResource:
+		h.report(w, r)
+	case kind != cardResource:
+		methodNotAllowed(w, "OPTIONS, PROPFIND, REPORT")
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		h.getCard(w, r, id)
+	case r.Method == http.MethodPut:
+		h.putCard(w, r, id)
+	case r.Method == http.MethodDelete:
+		h.deleteCard(w, r, id)
+	default:
+		methodNotAllowed(w, "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND")
+	}
+}
+
+// resolve returns what the path names, with the ID of the contact for a card
+func resolve(path string) (resourceKind, string) {
+	switch path {
+	case PrincipalPath, strings.TrimSuffix(PrincipalPath, "/"):
+		return principalResource, ""
+	case AddressBookPath, strings.TrimSuffix(AddressBookPath, "/"):
+		return addressBookResource, ""
+	}
+	name, found := strings.CutPrefix(path, AddressBookPath)
+	if !found || !strings.HasSuffix(name, cardExtension) {
+		return noResource, ""
+	}
+	id := strings.TrimSuffix(name, cardExtension)
+	if !validID(id) {
+		return noResource, ""
+	}
+	return cardResource, id
+}
+
+// validID tells if a card name can be used as the ID of a contact
+func validID(id string) bool {
+	if id == "" || id[0] == '.' {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == '@':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// cardHref returns the path of the card of a contact
+func cardHref(id string) string {
+	return AddressBookPath + id + cardExtension
+}
+
+// etag returns the entity tag of a contact, which changes with any of its details
+func etag(contact models.Contact) string {
+	data, _ := json.Marshal(contact)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etags returns the entity tag of every contact of the book by ID
+func (h *Handler) etags() (map[string]string, map[string]models.Contact) {
+	contacts := h.Book.ContactsByKey()
+	etags := make(map[string]string, len(contacts))
+	for id, contact := range contacts {
+		etags[id] = etag(contact)
+	}
+	return etags, contacts
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+// propfind answers the properties of the resource, and of its members with a depth of 1
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, kind resourceKind, id string) {
+	body, err := parseBody(http.MaxBytesReader(w, r.Body, maxCardSize))
+	if err != nil {
+		http.Error(w, "invalid XML body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body != nil && !body.is(davNS, "propfind") {
+		http.Error(w, "a DAV:propfind body is expected", http.StatusBadRequest)
+		return
+	}
+	depth := r.Header.Get("Depth")
+	if depth == "" {
+		depth = "infinity"
+	}
+
+	etags, contacts := h.etags()
+	token := h.sync.token(etags)
+	var resources []resource
+	switch kind {
+	case principalResource:
+		resources = append(resources, resource{kind: principalResource, href: PrincipalPath})
+		if depth != "0" {
+			resources = append(resources, resource{kind: addressBookResource, href: AddressBookPath, syncToken: token})
+		}
+	case addressBookResource:
+		resources = append(resources, resource{kind: addressBookResource, href: AddressBookPath, syncToken: token})
+		if depth != "0" {
+			var cards []response
+			for id, contact := range contacts {
+				cards = append(cards, h.describe(body, cardResourceOf(contact, etags[id])))
+			}
+			writeMultistatus(w, append(h.describeAll(body, resources), sortedResponses(cards)...), "")
+			return
+		}
+	case cardResource:
+		contact, found := contacts[id]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		resources = append(resources, cardResourceOf(contact, etags[id]))
+	}
+	writeMultistatus(w, h.describeAll(body, resources), "")
+}
+
+func (h *Handler) describeAll(body *element, resources []resource) []response {
+	responses := make([]response, 0, len(resources))
+	for _, res := range resources {
+		responses = append(responses, h.describe(body, res))
+	}
+	return responses
+}
+
+// describe answers the properties asked by the propfind body, every property but address-data when it is empty
+func (h *Handler) describe(body *element, res resource) response {
+	switch {
+	case body.child(davNS, "propname") != nil:
+		found := make([]string, 0)
+		for _, name := range res.names() {
+			found = append(found, renderElement(name, ""))
+		}
+		return response{href: res.href, found: found}
+	case body.child(davNS, "prop") != nil:
+		return res.properties(propNames(body.child(davNS, "prop")), "")
+	default:
+		return res.properties(res.names(), "")
+	}
+}
+
+// resource is a principal, the address book or a card, with what its properties are computed from
+type resource struct {
+	kind      resourceKind
+	href      string
+	syncToken string
+	contact   models.Contact
+	etag      string
+}
+
+func cardResourceOf(contact models.Contact, etag string) resource {
+	return resource{kind: cardResource, href: cardHref(contact.ID), contact: contact, etag: etag}
+}
+
+var (
+	resourceTypeName         = xml.Name{Space: davNS, Local: "resourcetype"}
+	displayNameName          = xml.Name{Space: davNS, Local: "displayname"}
+	currentUserPrincipalName = xml.Name{Space: davNS, Local: "current-user-principal"}
+	principalURLName         = xml.Name{Space: davNS, Local: "principal-URL"}
+	privilegeSetName         = xml.Name{Space: davNS, Local: "current-user-privilege-set"}
+	supportedReportSetName   = xml.Name{Space: davNS, Local: "supported-report-set"}
+	syncTokenName            = xml.Name{Space: davNS, Local: "sync-token"}
+	etagName                 = xml.Name{Space: davNS, Local: "getetag"}
+	contentTypeName          = xml.Name{Space: davNS, Local: "getcontenttype"}
+	homeSetName              = xml.Name{Space: cardNS, Local: "addressbook-home-set"}
+	descriptionName          = xml.Name{Space: cardNS, Local: "addressbook-description"}
+	supportedDataName        = xml.Name{Space: cardNS, Local: "supported-address-data"}
+	addressDataName          = xml.Name{Space: cardNS, Local: "address-data"}
+	ctagName                 = xml.Name{Space: csNS, Local: "getctag"}
+)
+
+// names returns the properties of the resource, address-data being only answered when asked for
+func (res resource) names() []xml.Name {
+	switch res.kind {
+	case principalResource:
+		return []xml.Name{resourceTypeName, displayNameName, currentUserPrincipalName, principalURLName, homeSetName}
+	case addressBookResource:
+		return []xml.Name{resourceTypeName, displayNameName, currentUserPrincipalName, privilegeSetName,
+			supportedReportSetName, syncTokenName, descriptionName, supportedDataName, ctagName}
+	default:
+		return []xml.Name{resourceTypeName, etagName, contentTypeName}
+	}
+}
+
+// properties answers the properties with the names, address-data in the vCard version when it is asked for
+func (res resource) properties(names []xml.Name, version string) response {
+	resp := response{href: res.href}
+	for _, name := range names {
+		if value, found := res.property(name, version); found {
+			resp.found = append(resp.found, renderElement(name, value))
+		} else {
+			resp.missing = append(resp.missing, name)
+		}
+	}
+	return resp
+}
+
+// property returns the inner XML of a property of the resource
+func (res resource) property(name xml.Name, version string) (string, bool) {
+	switch {
+	case name == resourceTypeName:
+		switch res.kind {
+		case principalResource:
+			return "<d:collection/><d:principal/>", true
+		case addressBookResource:
+			return "<d:collection/><card:addressbook/>", true
+		}
+		return "", true
+	case name == currentUserPrincipalName && res.kind != cardResource,
+		name == principalURLName && res.kind == principalResource,
+		name == homeSetName && res.kind == principalResource:
+		return renderHref(PrincipalPath), true
+	}
+
+	switch res.kind {
+	case principalResource:
+		if name == displayNameName {
+			return "Address book owner", true
+		}
+	case addressBookResource:
+		switch name {
+		case displayNameName:
+			return "Contacts", true
+		case descriptionName:
+			return "Contacts of the address book", true
+		case privilegeSetName:
+			return "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>" +
+				"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>" +
+				"<d:privilege><d:unbind/></d:privilege>", true
+		case supportedReportSetName:
+			return "<d:supported-report><d:report><card:addressbook-query/></d:report></d:supported-report>" +
+				"<d:supported-report><d:report><card:addressbook-multiget/></d:report></d:supported-report>" +
+				"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>", true
+		case supportedDataName:
+			return `<card:address-data-type content-type="text/vcard" version="3.0"/>` +
+				`<card:address-data-type content-type="text/vcard" version="4.0"/>`, true
+		case syncTokenName, ctagName:
+			return escape(res.syncToken), true
+		}
+	case cardResource:
+		switch name {
+		case etagName:
+			return escape(res.etag), true
+		case contentTypeName:
+			return "text/vcard; charset=utf-8", true
+		case addressDataName:
+			data, err := encodeCard(res.contact, version)
+			if err != nil {
+				return "", false
+			}
+			return escape(data), true
+		}
+	}
+	return "", false
+}
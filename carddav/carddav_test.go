@@ -0,0 +1,270 @@
+package carddav_test
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/carddav"
+	"GoAddressBook/carddav/carddavtest"
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	adaID   = "0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11"
+	alanID  = "6f1c0e52-9b4d-4a07-8f5e-2d3c4b5a6978"
+	graceID = "c2d9a8e1-5f4b-4e3a-9c7d-1a2b3c4d5e6f"
+)
+
+func newContact(id, firstName, lastName, number string) models.Contact {
+	return models.Contact{ID: id, FirstName: firstName, LastName: lastName,
+		Phones: []models.Phone{{Type: models.MobilePhone, Number: number, Primary: true}},
+		Emails: []models.Email{{Type: models.WorkEmail, Address: strings.ToLower(firstName) + "@example.com", Primary: true}}}
+}
+
+func href(id string) string {
+	return carddav.AddressBookPath + id + ".vcf"
+}
+
+// newClient serves an empty book with a client of it
+func newClient(t *testing.T) (*carddavtest.LocalClient, *addressbook.AddressBook) {
+	t.Helper()
+	book := addressbook.NewAddressBook(repository.NewMemoryRepository())
+	client := carddavtest.NewLocalClient(book)
+	t.Cleanup(client.Close)
+	return client, book
+}
+
+func TestDiscovery(t *testing.T) {
+	client, _ := newClient(t)
+	book, err := client.FindAddressBook(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book != carddav.AddressBookPath {
+		t.Errorf("address book = %q, want %q", book, carddav.AddressBookPath)
+	}
+}
+
+func TestPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	client, book := newClient(t)
+
+	ada := newContact(adaID, "Ada", "Lovelace", "+14155550100")
+	if err := client.PutCard(ctx, href(adaID), ada, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := book.GetContact(adaID); !found {
+		t.Fatal("the card isn't in the book under its UID")
+	}
+	card, err := client.GetCard(ctx, href(adaID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card.ETag == "" || card.Contact.FirstName != "Ada" || card.Contact.PhoneNumbers()[0] != "+14155550100" {
+		t.Errorf("card = %+v", card)
+	}
+	if err = client.PutCard(ctx, href(adaID), ada, ""); !errors.Is(err, carddavtest.PreconditionFailed) {
+		t.Errorf("creating an existing card: %v, want %v", err, carddavtest.PreconditionFailed)
+	}
+
+	ada.LastName = "King"
+	if err = client.PutCard(ctx, href(adaID), ada, card.ETag); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PutCard(ctx, href(adaID), ada, card.ETag); !errors.Is(err, carddavtest.PreconditionFailed) {
+		t.Errorf("update with a stale ETag: %v, want %v", err, carddavtest.PreconditionFailed)
+	}
+	if err = client.DeleteCard(ctx, href(adaID), card.ETag); !errors.Is(err, carddavtest.PreconditionFailed) {
+		t.Errorf("delete with a stale ETag: %v, want %v", err, carddavtest.PreconditionFailed)
+	}
+
+	updated, err := client.GetCard(ctx, href(adaID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Contact.LastName != "King" || updated.ETag == card.ETag {
+		t.Errorf("updated card = %+v", updated)
+	}
+	if err = client.DeleteCard(ctx, href(adaID), updated.ETag); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetCard(ctx, href(adaID)); !errors.Is(err, carddavtest.CardNotFound) {
+		t.Errorf("reading a deleted card: %v, want %v", err, carddavtest.CardNotFound)
+	}
+}
+
+func TestPutCardUID(t *testing.T) {
+	ctx := context.Background()
+	client, book := newClient(t)
+	if err := client.PutCard(ctx, href(adaID), newContact(adaID, "Ada", "Lovelace", "+14155550100"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		href   string
+		card   string
+		status int
+	}{
+		{name: "uid of another card", href: href(alanID), status: http.StatusConflict,
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:urn:uuid:" + adaID + "\r\nN:Turing;Alan;;;\r\nEMAIL:alan@example.com\r\nEND:VCARD\r\n"},
+		{name: "uid not the name", href: href(alanID), status: http.StatusCreated,
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:urn:uuid:" + graceID + "\r\nN:Turing;Alan;;;\r\nTEL:+14155550104\r\nEMAIL:alan@example.com\r\nEND:VCARD\r\n"},
+		{name: "uid taken by the name of another card", href: href("grace"), status: http.StatusConflict,
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:urn:uuid:" + graceID + "\r\nN:Hopper;Grace;;;\r\nTEL:+14155550105\r\nEMAIL:grace@example.com\r\nEND:VCARD\r\n"},
+		{name: "no uid", href: href("hopper"), status: http.StatusCreated,
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Hopper;Grace;;;\r\nTEL:+14155550102\r\nEMAIL:grace@example.com\r\nEND:VCARD\r\n"},
+		{name: "opaque uid", href: href("grace"), status: http.StatusCreated,
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:grace@example.com\r\nN:Hopper;Grace;;;\r\nTEL:+14155550103\r\nEMAIL:grace@example.com\r\nEND:VCARD\r\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodPut, client.BaseURL+test.href, strings.NewReader(test.card))
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Header.Set("Content-Type", "text/vcard")
+			resp, err := client.HTTPClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, test.status)
+			}
+		})
+	}
+	if contact, _ := book.GetContact(adaID); contact.FirstName != "Ada" {
+		t.Errorf("the card with the UID was replaced by %+v", contact)
+	}
+	if contact, _ := book.GetContact(alanID); contact.UID != "urn:uuid:"+graceID {
+		t.Errorf("UID = %q, want the one of the card", contact.UID)
+	}
+	if contact, _ := book.GetContact("grace"); contact.UID != "grace@example.com" {
+		t.Errorf("UID = %q, want the one of the card", contact.UID)
+	}
+}
+
+func TestQueryAndMultiget(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newClient(t)
+	for _, contact := range []models.Contact{
+		newContact(adaID, "Ada", "Lovelace", "+14155550100"),
+		newContact(alanID, "Alan", "Turing", "+14155550101"),
+	} {
+		if err := client.PutCard(ctx, href(contact.ID), contact, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		property, text string
+		want           []string
+	}{
+		{property: "FN", text: "lovelace", want: []string{adaID}},
+		{property: "TEL", text: "555", want: []string{adaID, alanID}},
+		{property: "UID", text: alanID, want: []string{alanID}},
+		{property: "EMAIL", text: "alan@", want: []string{alanID}},
+		{property: "ADR", text: "London", want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.property, func(t *testing.T) {
+			cards, err := client.Query(ctx, carddav.AddressBookPath, test.property, test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := cardIDs(cards); strings.Join(ids, ",") != strings.Join(test.want, ",") {
+				t.Errorf("cards = %v, want %v", ids, test.want)
+			}
+		})
+	}
+
+	cards, err := client.Multiget(ctx, carddav.AddressBookPath, []string{href(alanID), href(graceID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0].Contact.FirstName != "Alan" {
+		t.Errorf("multiget = %+v, want only Alan", cards)
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newClient(t)
+	ada := newContact(adaID, "Ada", "Lovelace", "+14155550100")
+	for _, contact := range []models.Contact{ada, newContact(alanID, "Alan", "Turing", "+14155550101")} {
+		if err := client.PutCard(ctx, href(contact.ID), contact, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	initial, err := client.Sync(ctx, carddav.AddressBookPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := cardIDs(initial.Changed); len(ids) != 2 || len(initial.Removed) != 0 || initial.SyncToken == "" {
+		t.Fatalf("initial sync = %+v", initial)
+	}
+
+	unchanged, err := client.Sync(ctx, carddav.AddressBookPath, initial.SyncToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unchanged.Changed) != 0 || len(unchanged.Removed) != 0 {
+		t.Errorf("sync without changes = %+v", unchanged)
+	}
+
+	ada.LastName = "King"
+	if err = client.PutCard(ctx, href(adaID), ada, etagOf(t, client, adaID)); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.DeleteCard(ctx, href(alanID), ""); err != nil {
+		t.Fatal(err)
+	}
+	grace := newContact(graceID, "Grace", "Hopper", "+14155550102")
+	if err = client.PutCard(ctx, href(graceID), grace, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	delta, err := client.Sync(ctx, carddav.AddressBookPath, initial.SyncToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := cardIDs(delta.Changed); strings.Join(ids, ",") != adaID+","+graceID {
+		t.Errorf("changed = %v, want Ada and Grace", ids)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != href(alanID) {
+		t.Errorf("removed = %v, want Alan", delta.Removed)
+	}
+	if delta.SyncToken == initial.SyncToken {
+		t.Error("the sync token didn't change")
+	}
+
+	if _, err = client.Sync(ctx, carddav.AddressBookPath, "http://example.com/unknown"); !errors.Is(err, carddav.InvalidSyncToken) {
+		t.Errorf("sync with an unknown token: %v, want %v", err, carddav.InvalidSyncToken)
+	}
+}
+
+func etagOf(t *testing.T, client *carddavtest.LocalClient, id string) string {
+	t.Helper()
+	card, err := client.GetCard(context.Background(), href(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return card.ETag
+}
+
+// cardIDs returns the sorted IDs of the cards
+func cardIDs(cards []carddavtest.Card) []string {
+	ids := make([]string, 0, len(cards))
+	for _, card := range cards {
+		ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(card.Href, carddav.AddressBookPath), ".vcf"))
+	}
+	sort.Strings(ids)
+	return ids
+}
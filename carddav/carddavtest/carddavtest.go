@@ -0,0 +1,30 @@
+// Package carddavtest speaks CardDAV the way the contact clients do, serving the endpoint of an address book on
+// a loopback port for tests
+package carddavtest
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/carddav"
+	"net/http"
+	"net/http/httptest"
+)
+
+// LocalClient is a Client of a CardDAV endpoint served in the same process on a loopback port,
+// for tests that want the whole HTTP stack
+type LocalClient struct {
+	*Client
+	server *httptest.Server
+}
+
+// NewLocalClient serves the address book on a loopback port and returns a client of it
+func NewLocalClient(book *addressbook.AddressBook) *LocalClient {
+	mux := http.NewServeMux()
+	carddav.NewHandler(book).Register(mux)
+	server := httptest.NewServer(mux)
+	return &LocalClient{Client: NewClient(server.URL), server: server}
+}
+
+// Close stops the server
+func (c *LocalClient) Close() {
+	c.server.Close()
+}
@@ -0,0 +1,404 @@
+package carddavtest
+
+import (
+	"GoAddressBook/carddav"
+	"GoAddressBook/models"
+	"GoAddressBook/vcard"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var (
+	CardNotFound       = errors.New("card not found")
+	PreconditionFailed = errors.New("the card changed since its ETag was read")
+	AddressBookMissing = errors.New("no address book found for the principal")
+)
+
+const (
+	cardExtension    = ".vcf"
+	vcardContentType = "text/vcard; charset=utf-8"
+)
+
+// Card is a vCard of the address book as a client sees it
+type Card struct {
+	Href    string
+	ETag    string
+	Contact models.Contact // only read by the calls asking for the address data
+}
+
+// SyncResult is the answer to a sync-collection report
+type SyncResult struct {
+	Changed   []Card   // cards added or changed, with their address data
+	Removed   []string // hrefs of the cards removed
+	SyncToken string   // token to pass to the next sync
+}
+
+// Client speaks CardDAV to a server the way the contact clients do
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string // scheme and host of the server, e.g. http://127.0.0.1:8080
+}
+
+// NewClient returns a Client of the server at the base URL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		HTTPClient: &http.Client{
+			// Redirects are followed by the discovery only, keeping the method and body of the request
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// FindAddressBook discovers the href of the address book from the well-known URL, as the clients set up with
+// only the server name do
+func (c *Client) FindAddressBook(ctx context.Context) (string, error) {
+	principal, err := c.findHref(ctx, carddav.WellKnownPath, "current-user-principal", 0)
+	if err != nil {
+		return "", err
+	}
+	home, err := c.findHref(ctx, principal, "addressbook-home-set", 0)
+	if err != nil {
+		return "", err
+	}
+	status, err := c.propfind(ctx, home, "1", "<d:prop><d:resourcetype/></d:prop>")
+	if err != nil {
+		return "", err
+	}
+	for _, resp := range status.Responses {
+		for _, propstat := range resp.Propstats {
+			if propstat.Prop.ResourceType != nil && propstat.Prop.ResourceType.AddressBook != nil {
+				return resp.Href, nil
+			}
+		}
+	}
+	return "", AddressBookMissing
+}
+
+// findHref reads a property holding an href, following the redirects of the path
+func (c *Client) findHref(ctx context.Context, path, property string, redirects int) (string, error) {
+	prefix := "d"
+	if property == "addressbook-home-set" {
+		prefix = "card"
+	}
+	request := fmt.Sprintf("<d:prop><%s:%s/></d:prop>", prefix, property)
+	resp, err := c.do(ctx, "PROPFIND", path, "application/xml; charset=utf-8", propfindBody(request), map[string]string{"Depth": "0"})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if location := resp.Header.Get("Location"); resp.StatusCode/100 == 3 && location != "" && redirects < 5 {
+		return c.findHref(ctx, location, property, redirects+1)
+	}
+	status, err := readMultistatus(resp, path)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range status.Responses {
+		for _, propstat := range r.Propstats {
+			switch {
+			case property == "current-user-principal" && propstat.Prop.CurrentUserPrincipal != nil:
+				return propstat.Prop.CurrentUserPrincipal.Href, nil
+			case property == "addressbook-home-set" && propstat.Prop.HomeSet != nil:
+				return propstat.Prop.HomeSet.Href, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s has no %s", path, property)
+}
+
+// ListCards returns the href and ETag of every card of the address book
+func (c *Client) ListCards(ctx context.Context, book string) ([]Card, error) {
+	status, err := c.propfind(ctx, book, "1", "<d:prop><d:getetag/><d:resourcetype/></d:prop>")
+	if err != nil {
+		return nil, err
+	}
+	var cards []Card
+	for _, resp := range status.Responses {
+		if card, ok := resp.card(); ok && strings.HasSuffix(card.Href, cardExtension) {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+// GetCard reads a card
+func (c *Client) GetCard(ctx context.Context, href string) (Card, error) {
+	resp, err := c.do(ctx, http.MethodGet, href, "", nil, map[string]string{"Accept": "text/vcard"})
+	if err != nil {
+		return Card{}, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, href); err != nil {
+		return Card{}, err
+	}
+	contacts, err := vcard.Decode(resp.Body)
+	if err != nil {
+		return Card{}, err
+	}
+	if len(contacts) != 1 {
+		return Card{}, fmt.Errorf("%s holds %d vCards", href, len(contacts))
+	}
+	contact := contacts[0]
+	contact.ID = cardID(href)
+	return Card{Href: href, ETag: resp.Header.Get("ETag"), Contact: contact}, nil
+}
+
+// PutCard writes the contact to the card, creating it when the ETag is empty and replacing it only while its ETag
+// is still the given one otherwise
+func (c *Client) PutCard(ctx context.Context, href string, contact models.Contact, etag string) error {
+	var card bytes.Buffer
+	if err := vcard.Encode(&card, []models.Contact{contact}, vcard.Version3); err != nil {
+		return err
+	}
+	headers := map[string]string{"If-None-Match": "*"}
+	if etag != "" {
+		headers = map[string]string{"If-Match": etag}
+	}
+	resp, err := c.do(ctx, http.MethodPut, href, vcardContentType, &card, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, href)
+}
+
+// DeleteCard removes the card, only while its ETag is still the given one unless it is empty
+func (c *Client) DeleteCard(ctx context.Context, href, etag string) error {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	resp, err := c.do(ctx, http.MethodDelete, href, "", nil, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, href)
+}
+
+// Multiget reads the cards with the hrefs in one report, leaving out the ones that don't exist
+func (c *Client) Multiget(ctx context.Context, book string, hrefs []string) ([]Card, error) {
+	var body strings.Builder
+	body.WriteString(`<card:addressbook-multiget xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">`)
+	body.WriteString("<d:prop><d:getetag/><card:address-data/></d:prop>")
+	for _, href := range hrefs {
+		body.WriteString("<d:href>" + escape(href) + "</d:href>")
+	}
+	body.WriteString("</card:addressbook-multiget>")
+	return c.reportCards(ctx, book, body.String())
+}
+
+// Query returns the cards with a value of the vCard property, such as FN, EMAIL or TEL, containing the text
+func (c *Client) Query(ctx context.Context, book, property, text string) ([]Card, error) {
+	body := fmt.Sprintf(`<card:addressbook-query xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">`+
+		`<d:prop><d:getetag/><card:address-data/></d:prop>`+
+		`<card:filter><card:prop-filter name="%s"><card:text-match match-type="contains">%s</card:text-match>`+
+		`</card:prop-filter></card:filter></card:addressbook-query>`, escape(property), escape(text))
+	return c.reportCards(ctx, book, body)
+}
+
+// Sync returns the cards changed and removed since the sync token, every card when it is empty.
+// carddav.InvalidSyncToken is returned when the server doesn't know the token anymore, the client then syncs from scratch.
+func (c *Client) Sync(ctx context.Context, book, token string) (SyncResult, error) {
+	body := `<d:sync-collection xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">` +
+		"<d:sync-token>" + escape(token) + "</d:sync-token><d:sync-level>1</d:sync-level>" +
+		"<d:prop><d:getetag/><card:address-data/></d:prop></d:sync-collection>"
+	status, err := c.report(ctx, book, body)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	result := SyncResult{SyncToken: status.SyncToken}
+	for _, resp := range status.Responses {
+		if strings.Contains(resp.Status, "404") {
+			result.Removed = append(result.Removed, resp.Href)
+			continue
+		}
+		card, err := resp.decodedCard()
+		if err != nil {
+			return SyncResult{}, err
+		}
+		result.Changed = append(result.Changed, card)
+	}
+	return result, nil
+}
+
+func (c *Client) reportCards(ctx context.Context, book, body string) ([]Card, error) {
+	status, err := c.report(ctx, book, body)
+	if err != nil {
+		return nil, err
+	}
+	var cards []Card
+	for _, resp := range status.Responses {
+		if resp.Status != "" && !strings.Contains(resp.Status, "200") {
+			continue
+		}
+		card, err := resp.decodedCard()
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+func (c *Client) report(ctx context.Context, book, body string) (multistatusBody, error) {
+	resp, err := c.do(ctx, "REPORT", book, "application/xml; charset=utf-8", strings.NewReader(xml.Header+body),
+		map[string]string{"Depth": "1"})
+	if err != nil {
+		return multistatusBody{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusForbidden {
+		data, _ := io.ReadAll(resp.Body)
+		if bytes.Contains(data, []byte("valid-sync-token")) {
+			return multistatusBody{}, carddav.InvalidSyncToken
+		}
+	}
+	return readMultistatus(resp, book)
+}
+
+func (c *Client) propfind(ctx context.Context, path, depth, prop string) (multistatusBody, error) {
+	resp, err := c.do(ctx, "PROPFIND", path, "application/xml; charset=utf-8", propfindBody(prop),
+		map[string]string{"Depth": depth})
+	if err != nil {
+		return multistatusBody{}, err
+	}
+	defer resp.Body.Close()
+	return readMultistatus(resp, path)
+}
+
+func propfindBody(prop string) io.Reader {
+	return strings.NewReader(xml.Header +
+		`<d:propfind xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">` + prop + "</d:propfind>")
+}
+
+// do sends a request to the path, or to the absolute URL the server answered
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	target := path
+	if parsed, err := url.Parse(path); err != nil || !parsed.IsAbs() {
+		target = c.BaseURL + path
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	return c.HTTPClient.Do(request)
+}
+
+// checkStatus turns the failures of a request into errors
+func checkStatus(resp *http.Response, href string) error {
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", href, CardNotFound)
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w", href, PreconditionFailed)
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("%s %s: %s: %s", resp.Request.Method, href, resp.Status, strings.TrimSpace(string(message)))
+}
+
+// multistatusBody is the part of a 207 Multi-Status body read by the client
+type multistatusBody struct {
+	Responses []multistatusResponse `xml:"DAV: response"`
+	SyncToken string                `xml:"DAV: sync-token"`
+}
+
+type multistatusResponse struct {
+	Href      string `xml:"DAV: href"`
+	Status    string `xml:"DAV: status"`
+	Propstats []struct {
+		Status string `xml:"DAV: status"`
+		Prop   struct {
+			ETag                 string        `xml:"DAV: getetag"`
+			AddressData          string        `xml:"urn:ietf:params:xml:ns:carddav address-data"`
+			CurrentUserPrincipal *hrefProperty `xml:"DAV: current-user-principal"`
+			HomeSet              *hrefProperty `xml:"urn:ietf:params:xml:ns:carddav addressbook-home-set"`
+			ResourceType         *struct {
+				AddressBook *struct{} `xml:"urn:ietf:params:xml:ns:carddav addressbook"`
+			} `xml:"DAV: resourcetype"`
+		} `xml:"DAV: prop"`
+	} `xml:"DAV: propstat"`
+}
+
+type hrefProperty struct {
+	Href string `xml:"DAV: href"`
+}
+
+// card returns the card of the response with the properties found
+func (r multistatusResponse) card() (Card, bool) {
+	for _, propstat := range r.Propstats {
+		if strings.Contains(propstat.Status, "200") {
+			return Card{Href: r.Href, ETag: propstat.Prop.ETag}, true
+		}
+	}
+	return Card{}, false
+}
+
+// decodedCard returns the card of the response with the contact of its address data
+func (r multistatusResponse) decodedCard() (Card, error) {
+	card := Card{Href: r.Href}
+	for _, propstat := range r.Propstats {
+		if !strings.Contains(propstat.Status, "200") {
+			continue
+		}
+		card.ETag = propstat.Prop.ETag
+		if propstat.Prop.AddressData == "" {
+			continue
+		}
+		contacts, err := vcard.Decode(strings.NewReader(propstat.Prop.AddressData))
+		if err != nil {
+			return Card{}, fmt.Errorf("%s: %w", r.Href, err)
+		}
+		if len(contacts) == 1 {
+			card.Contact = contacts[0]
+			card.Contact.ID = cardID(r.Href)
+		}
+	}
+	return card, nil
+}
+
+// cardID returns the ID of the contact of a card, the name of the card without extension
+func cardID(href string) string {
+	if parsed, err := url.Parse(href); err == nil {
+		href = parsed.Path
+	}
+	return strings.TrimSuffix(path.Base(href), cardExtension)
+}
+
+func readMultistatus(resp *http.Response, href string) (multistatusBody, error) {
+	if resp.StatusCode != http.StatusMultiStatus {
+		if err := checkStatus(resp, href); err != nil {
+			return multistatusBody{}, err
+		}
+		return multistatusBody{}, fmt.Errorf("%s %s: %s instead of a multi-status", resp.Request.Method, href, resp.Status)
+	}
+	var status multistatusBody
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return multistatusBody{}, fmt.Errorf("%s %s: %w", resp.Request.Method, href, err)
+	}
+	return status, nil
+}
+
+// escape escapes the text or attribute value of an XML element
+func escape(text string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
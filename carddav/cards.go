@@ -0,0 +1,182 @@
+package carddav
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"GoAddressBook/vcard"
+	"encoding/xml"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const vcardContentType = "text/vcard; charset=utf-8"
+
+var (
+	validAddressData = xml.Name{Space: cardNS, Local: "valid-address-data"}
+	maxResourceSize  = xml.Name{Space: cardNS, Local: "max-resource-size"}
+	noUIDConflict    = xml.Name{Space: cardNS, Local: "no-uid-conflict"}
+)
+
+// encodeCard writes the contact as a vCard, in version 3.0 unless another one is given
+func encodeCard(contact models.Contact, version string) (string, error) {
+	if version == "" {
+		version = vcard.Version3
+	}
+	var card strings.Builder
+	if err := vcard.Encode(&card, []models.Contact{contact}, version); err != nil {
+		return "", err
+	}
+	return card.String(), nil
+}
+
+// acceptedVersion returns the vCard version asked for by an Accept header or an address-data element
+func acceptedVersion(accept string) string {
+	if strings.Contains(accept, "version=4.0") || strings.Contains(accept, "version=\"4.0\"") {
+		return vcard.Version4
+	}
+	return vcard.Version3
+}
+
+// matches tells if an If-Match or If-None-Match header names the entity tag, * naming any existing card
+func matches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed tells if the If-Match and If-None-Match headers of a write don't hold for the current card,
+// etag being empty when there is none
+func preconditionFailed(r *http.Request, etag string) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && (etag == "" || !matches(ifMatch, etag)) {
+		return true
+	}
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	return ifNoneMatch != "" && etag != "" && matches(ifNoneMatch, etag)
+}
+
+// getCard answers the vCard of a contact
+func (h *Handler) getCard(w http.ResponseWriter, r *http.Request, id string) {
+	contact, found := h.Book.GetContact(id)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	tag := etag(contact)
+	w.Header().Set("ETag", tag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matches(ifNoneMatch, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	card, err := encodeCard(contact, acceptedVersion(r.Header.Get("Accept")))
+	if err != nil {
+		slog.Info("failed to encode vCard", "id", id, "err", err)
+		http.Error(w, "the card couldn't be encoded", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", vcardContentType)
+	_, _ = io.WriteString(w, card)
+}
+
+// putCard adds the vCard under the name of the resource, or replaces the contact with that ID.
+// The UID of the card is kept apart from the ID and can't be the one of another card, a card without UID getting one
+// from the ID.
+// No ETag is answered since the card is normalized, the client reading it back to get the stored one.
+func (h *Handler) putCard(w http.ResponseWriter, r *http.Request, id string) {
+	existing, found := h.Book.GetContact(id)
+	current := ""
+	if found {
+		current = etag(existing)
+	}
+	if preconditionFailed(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	contacts, err := vcard.Decode(http.MaxBytesReader(w, r.Body, maxCardSize))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusForbidden, maxResourceSize, "the card is larger than 1 MB")
+		return
+	case err != nil:
+		writeError(w, http.StatusForbidden, validAddressData, err.Error())
+		return
+	case len(contacts) != 1:
+		writeError(w, http.StatusForbidden, validAddressData, "exactly one vCard is expected")
+		return
+	}
+
+	contact := contacts[0]
+	contact.ID = id
+	if owner, taken := h.uidOwner(vcard.UID(contact)); taken && owner != id {
+		writeErrorElement(w, http.StatusConflict, renderElement(noUIDConflict, renderHref(cardHref(owner))))
+		return
+	}
+	if found {
+		contact.CreatedOn = existing.CreatedOn
+	}
+	contact.NormalizePrimary()
+	if err := utility.ValidateContact(h.Validator, contact); err != nil {
+		writeError(w, http.StatusForbidden, validAddressData, err.Error())
+		return
+	}
+
+	if found {
+		err = h.Book.UpdateContact(id, contact)
+	} else {
+		_, err = h.Book.AddContact(contact)
+	}
+	switch {
+	case errors.Is(err, addressbook.ContactAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		slog.Info("failed to store vCard", "id", id, "err", err)
+		http.Error(w, "the card couldn't be stored", http.StatusInternalServerError)
+	case found:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Location", cardHref(id))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// deleteCard removes the contact of the card
+func (h *Handler) deleteCard(w http.ResponseWriter, r *http.Request, id string) {
+	existing, found := h.Book.GetContact(id)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if preconditionFailed(r, etag(existing)) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if _, err := h.Book.DeleteContact(id); err != nil {
+		if errors.Is(err, addressbook.ContactNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		slog.Info("failed to delete card", "id", id, "err", err)
+		http.Error(w, "the card couldn't be deleted", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uidOwner returns the ID of the contact whose card has the UID
+func (h *Handler) uidOwner(uid string) (string, bool) {
+	for id, contact := range h.Book.ContactsByKey() {
+		if vcard.UID(contact) == uid {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,214 @@
+package carddav
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/vcard"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var validSyncToken = xml.Name{Space: davNS, Local: "valid-sync-token"}
+
+// report answers the addressbook-query, addressbook-multiget and sync-collection reports of the address book
+func (h *Handler) report(w http.ResponseWriter, r *http.Request) {
+	body, err := parseBody(http.MaxBytesReader(w, r.Body, maxCardSize))
+	if err != nil {
+		http.Error(w, "invalid XML body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case body.is(cardNS, "addressbook-multiget"):
+		h.multiget(w, body)
+	case body.is(cardNS, "addressbook-query"):
+		h.query(w, body)
+	case body.is(davNS, "sync-collection"):
+		h.syncCollection(w, body)
+	default:
+		writeError(w, http.StatusForbidden, xml.Name{Space: davNS, Local: "supported-report"}, "")
+	}
+}
+
+// reportProperties returns the properties asked by a report, with the vCard version of address-data
+func reportProperties(body *element) ([]xml.Name, string) {
+	prop := body.child(davNS, "prop")
+	if prop == nil {
+		return []xml.Name{etagName}, ""
+	}
+	version := ""
+	if addressData := prop.child(cardNS, "address-data"); addressData != nil {
+		version = acceptedVersion("version=" + addressData.attr("version", "3.0"))
+	}
+	return propNames(prop), version
+}
+
+// multiget answers the cards named by the hrefs of the report
+func (h *Handler) multiget(w http.ResponseWriter, body *element) {
+	names, version := reportProperties(body)
+	etags, contacts := h.etags()
+	var responses []response
+	for _, href := range body.childrenNamed(davNS, "href") {
+		target := href.content()
+		if parsed, err := url.Parse(target); err == nil {
+			target = parsed.Path
+		}
+		kind, id := resolve(path.Clean(target))
+		contact, found := contacts[id]
+		if kind != cardResource || !found {
+			responses = append(responses, response{href: href.content(), status: http.StatusNotFound})
+			continue
+		}
+		responses = append(responses, cardResourceOf(contact, etags[id]).properties(names, version))
+	}
+	writeMultistatus(w, responses, "")
+}
+
+// query answers the cards matching the filter of the report, up to its limit
+func (h *Handler) query(w http.ResponseWriter, body *element) {
+	names, version := reportProperties(body)
+	filter := body.child(cardNS, "filter")
+	limit := 0
+	if nresults := body.child(cardNS, "limit").child(cardNS, "nresults"); nresults != nil {
+		limit, _ = strconv.Atoi(nresults.content())
+	}
+
+	etags, contacts := h.etags()
+	var responses []response
+	for id, contact := range contacts {
+		if matchesFilter(filter, contact) {
+			responses = append(responses, cardResourceOf(contact, etags[id]).properties(names, version))
+		}
+	}
+	responses = sortedResponses(responses)
+	if limit > 0 && len(responses) > limit {
+		responses = responses[:limit]
+	}
+	writeMultistatus(w, responses, "")
+}
+
+// syncCollection answers the cards changed and removed since the sync token of the report,
+// every card when it has none, with the token of the current state
+func (h *Handler) syncCollection(w http.ResponseWriter, body *element) {
+	names, version := reportProperties(body)
+	token := body.child(davNS, "sync-token").content()
+	etags, contacts := h.etags()
+
+	var changed, removed []string
+	var current string
+	if token == "" {
+		for id := range etags {
+			changed = append(changed, id)
+		}
+		current = h.sync.token(etags)
+	} else {
+		var err error
+		changed, removed, current, err = h.sync.changesSince(token, etags)
+		if errors.Is(err, InvalidSyncToken) {
+			writeError(w, http.StatusForbidden, validSyncToken, err.Error())
+			return
+		}
+	}
+
+	responses := make([]response, 0, len(changed)+len(removed))
+	for _, id := range changed {
+		responses = append(responses, cardResourceOf(contacts[id], etags[id]).properties(names, version))
+	}
+	for _, id := range removed {
+		responses = append(responses, response{href: cardHref(id), status: http.StatusNotFound})
+	}
+	writeMultistatus(w, sortedResponses(responses), current)
+}
+
+// matchesFilter tells if the contact matches the prop-filters of an addressbook-query filter,
+// any of them unless its test is allof
+func matchesFilter(filter *element, contact models.Contact) bool {
+	propFilters := filter.childrenNamed(cardNS, "prop-filter")
+	if len(propFilters) == 0 {
+		return true
+	}
+	all := filter.attr("test", "anyof") == "allof"
+	for _, propFilter := range propFilters {
+		if matchesPropFilter(propFilter, contact) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+// matchesPropFilter tells if the values of a vCard property of the contact match the prop-filter
+func matchesPropFilter(propFilter *element, contact models.Contact) bool {
+	values := propertyValues(strings.ToUpper(propFilter.attr("name", "")), contact)
+	if propFilter.child(cardNS, "is-not-defined") != nil {
+		return len(values) == 0
+	}
+	textMatches := propFilter.childrenNamed(cardNS, "text-match")
+	if len(textMatches) == 0 {
+		return len(values) > 0
+	}
+	all := propFilter.attr("test", "anyof") == "allof"
+	for _, textMatch := range textMatches {
+		matched := false
+		for _, value := range values {
+			if matchesText(textMatch, value) {
+				matched = true
+				break
+			}
+		}
+		if matched != all {
+			return !all
+		}
+	}
+	return all
+}
+
+// matchesText applies a text-match to a value, without regard to case unless the collation is i;octet
+func matchesText(textMatch *element, value string) bool {
+	text := textMatch.content()
+	if textMatch.attr("collation", "i;unicode-casemap") != "i;octet" {
+		text, value = strings.ToLower(text), strings.ToLower(value)
+	}
+	var matched bool
+	switch textMatch.attr("match-type", "contains") {
+	case "equals":
+		matched = value == text
+	case "starts-with":
+		matched = strings.HasPrefix(value, text)
+	case "ends-with":
+		matched = strings.HasSuffix(value, text)
+	default:
+		matched = strings.Contains(value, text)
+	}
+	if textMatch.attr("negate-condition", "no") == "yes" {
+		return !matched
+	}
+	return matched
+}
+
+// propertyValues returns the values of a vCard property of the contact, as they are written in the card
+func propertyValues(name string, contact models.Contact) []string {
+	var values []string
+	switch name {
+	case "FN":
+		values = append(values, strings.TrimSpace(contact.FirstName+" "+contact.LastName))
+	case "N":
+		values = append(values, contact.LastName+";"+contact.FirstName)
+	case "UID":
+		values = append(values, vcard.UID(contact))
+	case "TEL":
+		values = contact.PhoneNumbers()
+	case "EMAIL":
+		for _, email := range contact.Emails {
+			values = append(values, email.Address)
+		}
+	case "ADR":
+		for _, address := range contact.Addresses {
+			values = append(values, strings.Join([]string{"", "", address.Street, address.City, address.State,
+				address.Zip, address.Country}, ";"))
+		}
+	}
+	return values
+}
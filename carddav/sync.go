@@ -0,0 +1,126 @@
+package carddav
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	syncTokenPrefix = "urn:goaddressbook:sync:"
+
+	// maxRevisions is the number of states of the book kept to answer sync-collection reports,
+	// clients with an older token get an invalid token error and sync from scratch
+	maxRevisions = 100
+)
+
+var InvalidSyncToken = errors.New("the sync token is unknown or expired")
+
+// revision is a state of the book, the ETag of every card by ID
+type revision struct {
+	number int
+	etags  map[string]string
+}
+
+// syncState numbers the successive states of the book seen by the clients, so a sync token can name one of them.
+// The book may be changed by the CLI, the REST API or gRPC as well, so the state is compared on each request
+// rather than recorded on each write.
+type syncState struct {
+	mutex     sync.Mutex
+	epoch     string // distinguishes the tokens of this process from the ones of a previous run
+	revisions []revision
+}
+
+func newSyncState() *syncState {
+	return &syncState{epoch: strconv.FormatInt(time.Now().UnixNano(), 36)}
+}
+
+// token records the current state of the book when it changed and returns the token naming it
+func (s *syncState) token(etags map[string]string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record(etags)
+}
+
+// changesSince returns the IDs of the cards changed and removed since the state named by the token,
+// and the token of the current state
+func (s *syncState) changesSince(token string, etags map[string]string) (changed, removed []string, current string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	number, err := s.parseToken(token)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	var since *revision
+	for i := range s.revisions {
+		if s.revisions[i].number == number {
+			since = &s.revisions[i]
+			break
+		}
+	}
+	if since == nil {
+		return nil, nil, "", InvalidSyncToken
+	}
+	for id, etag := range etags {
+		if since.etags[id] != etag {
+			changed = append(changed, id)
+		}
+	}
+	for id := range since.etags {
+		if _, found := etags[id]; !found {
+			removed = append(removed, id)
+		}
+	}
+	return changed, removed, s.record(etags), nil
+}
+
+// record adds the state as a new revision unless it is the last one, the mutex being held
+func (s *syncState) record(etags map[string]string) string {
+	if len(s.revisions) > 0 {
+		last := s.revisions[len(s.revisions)-1]
+		if sameETags(last.etags, etags) {
+			return s.format(last.number)
+		}
+	}
+	number := 1
+	if len(s.revisions) > 0 {
+		number = s.revisions[len(s.revisions)-1].number + 1
+	}
+	s.revisions = append(s.revisions, revision{number: number, etags: etags})
+	if len(s.revisions) > maxRevisions {
+		s.revisions = s.revisions[len(s.revisions)-maxRevisions:]
+	}
+	return s.format(number)
+}
+
+func (s *syncState) format(number int) string {
+	return fmt.Sprintf("%s%s-%d", syncTokenPrefix, s.epoch, number)
+}
+
+func (s *syncState) parseToken(token string) (int, error) {
+	rest, found := strings.CutPrefix(token, syncTokenPrefix+s.epoch+"-")
+	if !found {
+		return 0, InvalidSyncToken
+	}
+	number, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, InvalidSyncToken
+	}
+	return number, nil
+}
+
+func sameETags(first, second map[string]string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for id, etag := range first {
+		if second[id] != etag {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+package carddav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Namespaces of the WebDAV, CardDAV and CalendarServer properties
+const (
+	davNS  = "DAV:"
+	cardNS = "urn:ietf:params:xml:ns:carddav"
+	csNS   = "http://calendarserver.org/ns/"
+)
+
+// prefixes are used for the known namespaces in the responses, the others being declared on each element
+var prefixes = map[string]string{
+	davNS:  "d",
+	cardNS: "card",
+	csNS:   "cs",
+}
+
+// element is a node of the XML body of a request
+type element struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*element
+	text     strings.Builder
+}
+
+// parseBody reads the XML body of a request, returning nil when it is empty
+func parseBody(r io.Reader) (*element, error) {
+	decoder := xml.NewDecoder(r)
+	var stack []*element
+	var root *element
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if len(stack) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			e := &element{name: token.Name, attrs: token.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			} else if root == nil {
+				root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+		}
+	}
+}
+
+func (e *element) is(space, local string) bool {
+	return e != nil && e.name.Space == space && e.name.Local == local
+}
+
+// child returns the first child with the name, nil when there is none
+func (e *element) child(space, local string) *element {
+	if e == nil {
+		return nil
+	}
+	for _, child := range e.children {
+		if child.is(space, local) {
+			return child
+		}
+	}
+	return nil
+}
+
+// childrenNamed returns every child with the name
+func (e *element) childrenNamed(space, local string) []*element {
+	if e == nil {
+		return nil
+	}
+	var named []*element
+	for _, child := range e.children {
+		if child.is(space, local) {
+			named = append(named, child)
+		}
+	}
+	return named
+}
+
+// attr returns the value of the attribute, or the default value when it is missing
+func (e *element) attr(local, defaultValue string) string {
+	for _, attr := range e.attrs {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return defaultValue
+}
+
+// content returns the text of the element, empty when there is no element
+func (e *element) content() string {
+	if e == nil {
+		return ""
+	}
+	return strings.TrimSpace(e.text.String())
+}
+
+// propNames returns the names of the properties requested by a prop element
+func propNames(prop *element) []xml.Name {
+	names := make([]xml.Name, 0, len(prop.children))
+	for _, child := range prop.children {
+		names = append(names, child.name)
+	}
+	return names
+}
+
+// textEscaper leaves the quotes of ETags and the line breaks of vCards as they are
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escape escapes the text of an XML element
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// escapeAttr escapes the value of an XML attribute
+func escapeAttr(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+// renderElement writes an element with the inner XML, an empty one when inner is empty
+func renderElement(name xml.Name, inner string) string {
+	tag, declaration := name.Local, ""
+	if prefix, known := prefixes[name.Space]; known {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		declaration = fmt.Sprintf(` xmlns:x="%s"`, escapeAttr(name.Space))
+	}
+	if inner == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + inner + "</" + tag + ">"
+}
+
+// renderHref writes an href element
+func renderHref(href string) string {
+	return renderElement(xml.Name{Space: davNS, Local: "href"}, escape(href))
+}
+
+// response is a resource of a multi-status body, with the properties found and missing or only a status
+type response struct {
+	href    string
+	found   []string // rendered properties
+	missing []xml.Name
+	status  int // status of the resource itself, for the ones without properties
+}
+
+// multistatus writes a 207 Multi-Status body
+func writeMultistatus(w http.ResponseWriter, responses []response, syncToken string) {
+	var body strings.Builder
+	body.WriteString(xml.Header)
+	body.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, resp := range responses {
+		body.WriteString("<d:response>")
+		body.WriteString(renderHref(resp.href))
+		if resp.status != 0 {
+			body.WriteString(renderStatus(resp.status))
+		}
+		if len(resp.found) > 0 {
+			body.WriteString("<d:propstat><d:prop>")
+			body.WriteString(strings.Join(resp.found, ""))
+			body.WriteString("</d:prop>")
+			body.WriteString(renderStatus(http.StatusOK))
+			body.WriteString("</d:propstat>")
+		}
+		if len(resp.missing) > 0 {
+			body.WriteString("<d:propstat><d:prop>")
+			for _, name := range resp.missing {
+				body.WriteString(renderElement(name, ""))
+			}
+			body.WriteString("</d:prop>")
+			body.WriteString(renderStatus(http.StatusNotFound))
+			body.WriteString("</d:propstat>")
+		}
+		body.WriteString("</d:response>")
+	}
+	if syncToken != "" {
+		body.WriteString(renderElement(xml.Name{Space: davNS, Local: "sync-token"}, escape(syncToken)))
+	}
+	body.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(w, body.String())
+}
+
+func renderStatus(status int) string {
+	return fmt.Sprintf("<d:status>HTTP/1.1 %d %s</d:status>", status, http.StatusText(status))
+}
+
+// writeError answers a status with a DAV:error body naming the precondition that failed
+func writeError(w http.ResponseWriter, status int, precondition xml.Name, description string) {
+	inner := renderElement(precondition, "")
+	if description != "" {
+		inner += renderElement(xml.Name{Space: davNS, Local: "responsedescription"}, escape(description))
+	}
+	writeErrorElement(w, status, inner)
+}
+
+// writeErrorElement answers a status with a DAV:error body holding the rendered elements
+func writeErrorElement(w http.ResponseWriter, status int, inner string) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, xml.Header+`<d:error xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">`+inner+"</d:error>")
+}
+
+// sortedResponses orders the responses by href so listings are stable
+func sortedResponses(responses []response) []response {
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].href < responses[j].href
+	})
+	return responses
+}
@@ -5,14 +5,16 @@ import (
 	"GoAddressBook/constants"
 	"GoAddressBook/i18n"
 	"GoAddressBook/models"
+	"GoAddressBook/output"
 	"GoAddressBook/utility"
-	"encoding/json"
 	"fmt"
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/chzyer/readline"
 	"github.com/go-playground/validator/v10"
 	"github.com/sagikazarmark/slog-shim"
 	"github.com/spf13/viper"
+	"os"
+	"sort"
 	"time"
 )
 
@@ -48,8 +50,19 @@ func (instance *Cli) Menu() {
 	actionsString, _ := instance.I18n.T(constants.Actions, nil)
 	listString, _ := instance.I18n.T(constants.List, nil)
 	createContact, _ := instance.I18n.T(constants.Create, nil)
+	updateContact, _ := instance.I18n.T(constants.Update, nil)
+	deleteContact, _ := instance.I18n.T(constants.Delete, nil)
 	searchByPhone, _ := instance.I18n.T(constants.SearchByPhoneNumber, nil)
 	searchByName, _ := instance.I18n.T(constants.SearchByName, nil)
+	search, _ := instance.I18n.T(constants.Search, nil)
+	queryString, _ := instance.I18n.T(constants.Query, nil)
+	importVCard, _ := instance.I18n.T(constants.ImportVCard, nil)
+	exportVCard, _ := instance.I18n.T(constants.ExportVCard, nil)
+	importCSV, _ := instance.I18n.T(constants.ImportCSV, nil)
+	exportCSV, _ := instance.I18n.T(constants.ExportCSV, nil)
+	importLDIF, _ := instance.I18n.T(constants.ImportLDIF, nil)
+	exportLDIF, _ := instance.I18n.T(constants.ExportLDIF, nil)
+	findDuplicates, _ := instance.I18n.T(constants.FindDuplicates, nil)
 	closeString, _ := instance.I18n.T(constants.Close, nil)
 	unknownChoiceString, _ := instance.I18n.T(constants.UnknownChoice, nil)
 
@@ -58,9 +71,20 @@ func (instance *Cli) Menu() {
 		Message: actionsString,
 		Options: []string{
 			createContact,
+			updateContact,
+			deleteContact,
 			searchByName,
 			searchByPhone,
+			search,
+			queryString,
 			listString,
+			importVCard,
+			exportVCard,
+			importCSV,
+			exportCSV,
+			importLDIF,
+			exportLDIF,
+			findDuplicates,
 			closeString,
 		},
 	}
@@ -73,12 +97,34 @@ func (instance *Cli) Menu() {
 		case 0:
 			instance.CreateContact()
 		case 1:
-			instance.GetContactDetailsByName()
+			instance.UpdateContact()
 		case 2:
-			instance.GetContactDetailsByPhoneNumber()
+			instance.DeleteContact()
 		case 3:
-			instance.ListContacts()
+			instance.GetContactDetailsByName()
 		case 4:
+			instance.GetContactDetailsByPhoneNumber()
+		case 5:
+			instance.SearchContacts()
+		case 6:
+			instance.QueryContacts()
+		case 7:
+			instance.ListContacts()
+		case 8:
+			instance.ImportVCard()
+		case 9:
+			instance.ExportVCard()
+		case 10:
+			instance.ImportCSV()
+		case 11:
+			instance.ExportCSV()
+		case 12:
+			instance.ImportLDIF()
+		case 13:
+			instance.ExportLDIF()
+		case 14:
+			instance.FindDuplicates()
+		case 15:
 			quit = true
 		default:
 			println(unknownChoiceString)
@@ -89,96 +135,192 @@ func (instance *Cli) Menu() {
 	println(closingString)
 	_ = instance.Reader.Close()
 }
+
+// ListContacts pages through the contacts sorted by the chosen field
 func (instance *Cli) ListContacts() {
 	listingString, _ := instance.I18n.T(constants.ContactsListing, nil)
+	sortByString, _ := instance.I18n.T(constants.SortBy, nil)
+	nextPageString, _ := instance.I18n.T(constants.NextPage, nil)
 	println(listingString)
-	contacts := instance.Book.ListAllContacts()
-	if len(contacts) == 0 {
-		println("No contacts found in collection: ")
-		return
+
+	options := addressbook.ListOptions{
+		SortBy: instance.selectOption(sortByString, addressbook.SortFields, addressbook.SortByName),
+		Limit:  viper.GetInt(constants.ListPageSize),
 	}
-	for _, contact := range contacts {
-		println(contact)
-		println("--------->")
+	for {
+		page, err := instance.Book.List(options)
+		if err != nil {
+			println("Failed to list contacts,", "err: ", err.Error())
+			return
+		}
+		if len(page.Contacts) == 0 && options.Cursor == "" {
+			println("No contacts found in collection: ")
+			return
+		}
+		instance.printContacts(page.Contacts)
+		if page.NextCursor == "" || !instance.confirm(nextPageString) {
+			return
+		}
+		options.Cursor = page.NextCursor
 	}
 }
 
-// CreateContact Create prompts the user to add a contact using the command line interface
 func (instance *Cli) CreateContact() {
 	addingString, _ := instance.I18n.T(constants.ContactAdding, nil)
 	firstNameString, _ := instance.I18n.T(constants.FullName, nil)
-	phoneNumber, _ := instance.I18n.T(constants.Phone, nil)
-	eMailAddress, _ := instance.I18n.T(constants.Email, nil)
-	addressDetails, _ := instance.I18n.T(constants.Address, nil)
-	street, _ := instance.I18n.T(constants.Street, nil)
-	city, _ := instance.I18n.T(constants.City, nil)
-	state, _ := instance.I18n.T(constants.State, nil)
-	zip, _ := instance.I18n.T(constants.Zip, nil)
-	country, _ := instance.I18n.T(constants.Country, nil)
 
 	fullName := instance.readLine(firstNameString)
 	firstName, _, lastName := utility.GetFirstMiddleAndLastNamesFromFullName(fullName)
 	println(addingString)
 	contact := models.Contact{
-		FirstName:    firstName,
-		LastName:     lastName,
-		PhoneNumber:  instance.readLine(phoneNumber),
-		EmailAddress: instance.readLine(eMailAddress),
-		CreatedOn:    time.Now(),
-	}
-
-	println(addressDetails)
-	address := models.Address{
-		Type:    constants.AddressType,
-		Street:  instance.readLine(street),
-		City:    instance.readLine(city),
-		State:   instance.readLine(state),
-		Zip:     instance.readLine(zip),
-		Country: instance.readLine(country),
+		FirstName: firstName,
+		LastName:  lastName,
+		Phones:    instance.readPhones(nil),
+		Emails:    instance.readEmails(nil),
+		Addresses: instance.readAddresses(nil),
+		CreatedOn: time.Now(),
 	}
-	contact.Addresses = address
+	contact.NormalizePrimary()
 
-	err := utility.RequestBodyValidator(contact)
-	if err != nil {
-		println("Requested data is invalid", "err", err)
-		println(constants.LineSeparator)
+	if !instance.validateContact(&contact) {
 		return
 	}
-
-	validationErr := instance.Validator.Struct(contact)
-	if validationErr != nil {
-		println("failed to validate request", "err: ", utility.ParseValidatorErrMessage(validationErr).Error())
+	if _, err := instance.Book.AddContact(contact); err != nil {
+		println("failed to add contact", "err: ", err.Error())
 		println(constants.LineSeparator)
 		return
 	}
-	instance.Book.AddContact(contact)
 	addedString, _ := instance.I18n.T(constants.ContactAdded, map[string]interface{}{
 		constants.FirstName:   contact.FirstName,
 		constants.LastName:    contact.LastName,
-		constants.PhoneNumber: contact.PhoneNumber,
-		constants.Email:       contact.EmailAddress,
-		constants.Address:     constants.Address,
+		constants.Name:        contact.FirstName + " " + contact.LastName,
+		constants.PhoneNumber: contact.PrimaryPhone().Number,
+		constants.Email:       contact.PrimaryEmail().Address,
+		constants.Address:     contact.PrimaryAddress().City,
 	})
 	println(addedString)
 	println(constants.LineSeparator)
 }
 
+// UpdateContact prompts the user to pick a contact and edit its details using the command line interface
+func (instance *Cli) UpdateContact() {
+	updatingString, _ := instance.I18n.T(constants.ContactUpdating, nil)
+	id, found := instance.selectContact(updatingString)
+	if !found {
+		return
+	}
+	existing, found := instance.Book.GetContact(id)
+	if !found {
+		println("Contact details not found,", "ID:", id)
+		println(constants.LineSeparator)
+		return
+	}
+
+	firstNameString, _ := instance.I18n.T(constants.FullName, nil)
+	fullName := instance.readLineWithDefault(firstNameString, existing.FirstName+" "+existing.LastName)
+	firstName, _, lastName := utility.GetFirstMiddleAndLastNamesFromFullName(fullName)
+	contact := models.Contact{
+		FirstName: firstName,
+		LastName:  lastName,
+		Phones:    instance.readPhones(existing.Phones),
+		Emails:    instance.readEmails(existing.Emails),
+		Addresses: instance.readAddresses(existing.Addresses),
+		CreatedOn: existing.CreatedOn,
+	}
+	contact.NormalizePrimary()
+
+	if !instance.validateContact(&contact) {
+		return
+	}
+	if err := instance.Book.UpdateContact(id, contact); err != nil {
+		println("failed to update contact", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	updatedString, _ := instance.I18n.T(constants.ContactUpdated, map[string]interface{}{
+		constants.Name: contact.FirstName + " " + contact.LastName,
+	})
+	println(updatedString)
+	println(constants.LineSeparator)
+}
+
+// DeleteContact prompts the user to pick a contact and removes it from the book
+func (instance *Cli) DeleteContact() {
+	deletingString, _ := instance.I18n.T(constants.ContactDeleting, nil)
+	id, found := instance.selectContact(deletingString)
+	if !found {
+		return
+	}
+	contact, err := instance.Book.DeleteContact(id)
+	if err != nil {
+		println("failed to delete contact", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	deletedString, _ := instance.I18n.T(constants.ContactDeleted, map[string]interface{}{
+		constants.Name: contact.FirstName + " " + contact.LastName,
+	})
+	println(deletedString)
+	println(constants.LineSeparator)
+}
+
+// GetContactDetailsByName prints the contacts matching the name exactly, by prefix or approximately, best matches first
 func (instance *Cli) GetContactDetailsByName() {
 	searchByName, _ := instance.I18n.T(constants.FullName, nil)
 	name := instance.readLine(searchByName)
-	contacts := instance.Book.SearchByName(name)
-	if contacts == nil {
+	matches := instance.Book.SearchNames(name, addressbook.NameSearchOptions{
+		Prefix:      true,
+		MaxDistance: viper.GetInt(constants.SearchMaxDistance),
+		Limit:       viper.GetInt(constants.SearchLimit),
+		Phonetic:    viper.GetBool(constants.SearchPhonetic),
+	})
+	if len(matches) == 0 {
 		println("Contacts not found for User ", "Name:", name)
 		println(constants.LineSeparator)
 		return
 	}
 	println("List of contact details of user by name,", "Name:", name)
-	for _, actualContact := range contacts {
-		actualContactByte, _ := json.Marshal(actualContact)
-		actualContactByteString := string(actualContactByte)
-		println(actualContactByteString)
+	contacts := make([]models.Contact, 0, len(matches))
+	for _, match := range matches {
+		contacts = append(contacts, match.Contact)
+	}
+	instance.printContacts(contacts)
+}
+
+// SearchContacts prints the contacts matching a full-text query over every field
+func (instance *Cli) SearchContacts() {
+	searchQuery, _ := instance.I18n.T(constants.SearchQuery, nil)
+	query := instance.readLine(searchQuery)
+	contacts, err := instance.Book.Search(query)
+	if err != nil {
+		println("Invalid search query,", "err: ", err.Error())
 		println(constants.LineSeparator)
+		return
 	}
+	if len(contacts) == 0 {
+		println("Contacts not found for search query,", "Query:", query)
+		println(constants.LineSeparator)
+		return
+	}
+	instance.printContacts(contacts)
+}
+
+// QueryContacts prints the contacts filtered and sorted by a structured query
+func (instance *Cli) QueryContacts() {
+	queryPrompt, _ := instance.I18n.T(constants.QueryPrompt, nil)
+	query := instance.readLine(queryPrompt)
+	contacts, err := instance.Book.Query(query)
+	if err != nil {
+		println("Invalid query,", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	if len(contacts) == 0 {
+		println("Contacts not found for query,", "Query:", query)
+		println(constants.LineSeparator)
+		return
+	}
+	instance.printContacts(contacts)
 }
 
 func (instance *Cli) GetContactDetailsByPhoneNumber() {
@@ -190,9 +332,57 @@ func (instance *Cli) GetContactDetailsByPhoneNumber() {
 		println(constants.LineSeparator)
 		return
 	}
-	actualContactByte, _ := json.Marshal(actualContact)
-	actualContactByteString := string(actualContactByte)
-	println(actualContactByteString)
+	instance.printContacts([]models.Contact{actualContact})
+}
+
+// selectContact lets the user pick one contact of the book and returns its ID, false when cancelled
+func (instance *Cli) selectContact(message string) (string, bool) {
+	contacts := instance.Book.ContactsByKey()
+	if len(contacts) == 0 {
+		println("No contacts found in collection: ")
+		println(constants.LineSeparator)
+		return "", false
+	}
+
+	options := make([]string, 0, len(contacts)+1)
+	labels := make(map[string]string, len(contacts))
+	keys := make([]string, 0, len(contacts))
+	for key, contact := range contacts {
+		keys = append(keys, key)
+		labels[key] = fmt.Sprintf("%s %s (%s)", contact.FirstName, contact.LastName, contact.PrimaryPhone().Number)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if labels[keys[i]] != labels[keys[j]] {
+			return labels[keys[i]] < labels[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		options = append(options, labels[key])
+	}
+	cancelString, _ := instance.I18n.T(constants.Cancel, nil)
+	options = append(options, cancelString)
+
+	var choice int
+	prompt := &survey.Select{
+		Message: message,
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &choice); err != nil || choice >= len(keys) {
+		return "", false
+	}
+	return keys[choice], true
+}
+
+// printContacts prints the contacts in the configured output format, as a table by default
+func (instance *Cli) printContacts(contacts []models.Contact) {
+	format, err := output.Parse(viper.GetString(constants.OutputFormat))
+	if err != nil {
+		format = output.Format{Name: output.Table}
+	}
+	if err := format.Write(os.Stdout, contacts); err != nil {
+		println("failed to print contacts", "err: ", err.Error())
+	}
 	println(constants.LineSeparator)
 }
 
@@ -206,3 +396,14 @@ func (instance *Cli) readLine(prompt string) string {
 	}
 	return line
 }
+
+// readLineWithDefault reads a line pre-filled with the current value so the user can keep or edit it
+func (instance *Cli) readLineWithDefault(prompt string, current string) string {
+	println(prompt)
+	line, err := instance.Reader.ReadlineWithDefault(current)
+	if err != nil {
+		slog.Info("Error occurred while reading command line", "err:", err)
+		panic(err)
+	}
+	return line
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// readPhones lets the user edit the existing phones, clearing a number removes it, then add as many as wanted.
+// At least one phone is asked for when there is none.
+func (instance *Cli) readPhones(existing []models.Phone) []models.Phone {
+	phonesString, _ := instance.I18n.T(constants.Phones, nil)
+	phoneNumber, _ := instance.I18n.T(constants.Phone, nil)
+	typeString, _ := instance.I18n.T(constants.Type, nil)
+	addPhone, _ := instance.I18n.T(constants.AddPhone, nil)
+
+	println(phonesString)
+	phones := make([]models.Phone, 0, len(existing))
+	for _, phone := range existing {
+		phone.Number = instance.readLineWithDefault(phoneNumber, phone.Number)
+		if phone.Number != "" {
+			phones = append(phones, phone)
+		}
+	}
+	for len(phones) == 0 || instance.confirm(addPhone) {
+		phones = append(phones, models.Phone{
+			Type:   instance.selectOption(typeString, models.PhoneTypes, models.MobilePhone),
+			Number: instance.readLine(phoneNumber),
+		})
+	}
+	return phones
+}
+
+// readEmails lets the user edit the existing emails, clearing an address removes it, then add as many as wanted.
+// At least one email is asked for when there is none.
+func (instance *Cli) readEmails(existing []models.Email) []models.Email {
+	emailsString, _ := instance.I18n.T(constants.Emails, nil)
+	eMailAddress, _ := instance.I18n.T(constants.Email, nil)
+	typeString, _ := instance.I18n.T(constants.Type, nil)
+	addEmail, _ := instance.I18n.T(constants.AddEmail, nil)
+
+	println(emailsString)
+	emails := make([]models.Email, 0, len(existing))
+	for _, email := range existing {
+		email.Address = instance.readLineWithDefault(eMailAddress, email.Address)
+		if email.Address != "" {
+			emails = append(emails, email)
+		}
+	}
+	for len(emails) == 0 || instance.confirm(addEmail) {
+		emails = append(emails, models.Email{
+			Type:    instance.selectOption(typeString, models.EmailTypes, models.PersonalEmail),
+			Address: instance.readLine(eMailAddress),
+		})
+	}
+	return emails
+}
+
+// readAddresses lets the user edit the existing addresses, clearing every field removes one, then add as many as wanted.
+// At least one address is asked for when there is none.
+func (instance *Cli) readAddresses(existing []models.Address) []models.Address {
+	addressDetails, _ := instance.I18n.T(constants.Address, nil)
+	typeString, _ := instance.I18n.T(constants.Type, nil)
+	addAddress, _ := instance.I18n.T(constants.AddAddress, nil)
+
+	addresses := make([]models.Address, 0, len(existing))
+	for _, address := range existing {
+		println(addressDetails)
+		address = instance.readAddress(address)
+		if address.Street != "" || address.City != "" || address.State != "" || address.Zip != "" || address.Country != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	for len(addresses) == 0 || instance.confirm(addAddress) {
+		println(addressDetails)
+		address := models.Address{Type: instance.selectOption(typeString, models.AddressTypes, models.PersonalAddress)}
+		addresses = append(addresses, instance.readAddress(address))
+	}
+	return addresses
+}
+
+// readAddress prompts every field of the address, pre-filled with its current value
+func (instance *Cli) readAddress(address models.Address) models.Address {
+	street, _ := instance.I18n.T(constants.Street, nil)
+	city, _ := instance.I18n.T(constants.City, nil)
+	state, _ := instance.I18n.T(constants.State, nil)
+	zip, _ := instance.I18n.T(constants.Zip, nil)
+	country, _ := instance.I18n.T(constants.Country, nil)
+
+	address.Street = instance.readLineWithDefault(street, address.Street)
+	address.City = instance.readLineWithDefault(city, address.City)
+	address.State = instance.readLineWithDefault(state, address.State)
+	address.Zip = instance.readLineWithDefault(zip, address.Zip)
+	address.Country = instance.readLineWithDefault(country, address.Country)
+	return address
+}
+
+// confirm asks a yes or no question, defaulting to no
+func (instance *Cli) confirm(message string) bool {
+	answer := false
+	_ = survey.AskOne(&survey.Confirm{Message: message}, &answer)
+	return answer
+}
+
+// selectOption lets the user pick one of the options, starting on the current one
+func (instance *Cli) selectOption(message string, options []string, current string) string {
+	answer := current
+	_ = survey.AskOne(&survey.Select{Message: message, Options: options, Default: current}, &answer)
+	return answer
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/csvcontacts"
+	"GoAddressBook/ldif"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"GoAddressBook/vcard"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+)
+
+// ImportVCard adds the contacts of a .vcf file, reporting the cards that were rejected
+func (instance *Cli) ImportVCard() {
+	instance.importFile(vcard.Decode)
+}
+
+// ExportVCard writes one or every contact to a .vcf file
+func (instance *Cli) ExportVCard() {
+	contacts, ok := instance.contactsToExport()
+	if !ok {
+		return
+	}
+	versionString, _ := instance.I18n.T(constants.VCardVersion, nil)
+	version := instance.selectOption(versionString, vcard.Versions, vcard.Version4)
+	instance.exportFile(len(contacts), func(w io.Writer) error {
+		return vcard.Encode(w, contacts, version)
+	})
+}
+
+// ImportCSV adds the contacts of a CSV file exported from Google Contacts, Outlook or this book, reporting the rows that were rejected
+func (instance *Cli) ImportCSV() {
+	instance.importFile(csvcontacts.Decode)
+}
+
+// ExportCSV writes the whole book to a CSV file
+func (instance *Cli) ExportCSV() {
+	contacts := instance.Book.AllContacts()
+	instance.exportFile(len(contacts), func(w io.Writer) error {
+		return csvcontacts.Encode(w, contacts)
+	})
+}
+
+// ImportLDIF adds the inetOrgPerson entries of an LDIF file, reporting the entries that were rejected
+func (instance *Cli) ImportLDIF() {
+	instance.importFile(ldif.Decode)
+}
+
+// ExportLDIF writes one or every contact to an LDIF file, as entries under the configured base DN
+func (instance *Cli) ExportLDIF() {
+	contacts, ok := instance.contactsToExport()
+	if !ok {
+		return
+	}
+	instance.exportFile(len(contacts), func(w io.Writer) error {
+		return ldif.Encode(w, contacts, viper.GetString(constants.LdifBaseDN))
+	})
+}
+
+// importFile asks for a file, decodes its contacts and adds the valid ones
+func (instance *Cli) importFile(decode func(r io.Reader) ([]models.Contact, error)) {
+	filePathString, _ := instance.I18n.T(constants.FilePath, nil)
+	path := instance.readLine(filePathString)
+
+	file, err := os.Open(path)
+	if err != nil {
+		println("failed to open file", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	defer file.Close()
+
+	contacts, err := decode(file)
+	if err != nil {
+		println("failed to read contacts", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	instance.printImport(instance.Book.Import(contacts, instance.Validator))
+}
+
+// exportFile asks for a file and writes the encoded contacts to it
+func (instance *Cli) exportFile(count int, encode func(w io.Writer) error) {
+	filePathString, _ := instance.I18n.T(constants.FilePath, nil)
+	path := instance.readLine(filePathString)
+
+	file, err := os.Create(path)
+	if err != nil {
+		println("failed to create file", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	err = encode(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		println("failed to write contacts", "err: ", err.Error())
+		println(constants.LineSeparator)
+		return
+	}
+	exportedString, _ := instance.I18n.T(constants.ContactsExported, map[string]interface{}{
+		"Count": count,
+		"Path":  path,
+	})
+	println(exportedString)
+	println(constants.LineSeparator)
+}
+
+// contactsToExport asks whether to export every contact or a single one
+func (instance *Cli) contactsToExport() ([]models.Contact, bool) {
+	exportAllString, _ := instance.I18n.T(constants.ExportAll, nil)
+	if instance.confirm(exportAllString) {
+		return instance.Book.AllContacts(), true
+	}
+	exportingString, _ := instance.I18n.T(constants.ContactExporting, nil)
+	id, ok := instance.selectContact(exportingString)
+	if !ok {
+		return nil, false
+	}
+	contact, found := instance.Book.GetContact(id)
+	return []models.Contact{contact}, found
+}
+
+func (instance *Cli) printImport(added int, failures []addressbook.ImportFailure) {
+	for _, failure := range failures {
+		var validationErr *utility.ValidationError
+		if errors.As(failure.Err, &validationErr) {
+			validationErr.Localize(instance.I18n)
+		}
+		println(fmt.Sprintf("#%d %s %s:", failure.Index, failure.Contact.FirstName, failure.Contact.LastName), failure.Err.Error())
+	}
+	importedString, _ := instance.I18n.T(constants.ContactsImported, map[string]interface{}{
+		"Added":    added,
+		"Rejected": len(failures),
+	})
+	println(importedString)
+	println(constants.LineSeparator)
+}
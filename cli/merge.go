@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"fmt"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+// columnWidth is the width of each contact in the side by side view of duplicates
+const columnWidth = 36
+
+// FindDuplicates walks through the likely duplicate contacts, best first, merging the pairs the user confirms
+func (instance *Cli) FindDuplicates() {
+	duplicates := instance.Book.FindDuplicates(viper.GetFloat64(constants.DuplicateThreshold))
+	if len(duplicates) == 0 {
+		noDuplicatesString, _ := instance.I18n.T(constants.NoDuplicates, nil)
+		println(noDuplicatesString)
+		println(constants.LineSeparator)
+		return
+	}
+
+	mergeString, _ := instance.I18n.T(constants.MergeContacts, nil)
+	removed := make(map[string]bool)
+	for _, duplicate := range duplicates {
+		if removed[duplicate.First.ID] || removed[duplicate.Second.ID] {
+			continue
+		}
+		// A contact kept by an earlier merge has new details
+		first, found := instance.Book.GetContact(duplicate.First.ID)
+		second, foundSecond := instance.Book.GetContact(duplicate.Second.ID)
+		if !found || !foundSecond {
+			continue
+		}
+
+		instance.printSideBySide(first, second, duplicate.Score)
+		if !instance.confirm(mergeString) {
+			continue
+		}
+		merged := instance.pickFields(first, second)
+		if err := instance.Book.MergeContacts(first.ID, second.ID, merged); err != nil {
+			println("failed to merge contacts", "err: ", err.Error())
+			println(constants.LineSeparator)
+			continue
+		}
+		removed[second.ID] = true
+
+		mergedString, _ := instance.I18n.T(constants.ContactsMerged, map[string]interface{}{
+			constants.Name: merged.FirstName + " " + merged.LastName,
+		})
+		println(mergedString)
+		println(constants.LineSeparator)
+	}
+}
+
+// printSideBySide shows the details of both contacts in two columns
+func (instance *Cli) printSideBySide(first, second models.Contact, score float64) {
+	println(constants.LineSeparator)
+	println(fmt.Sprintf("score: %.0f%%", score*100))
+	rows := []struct {
+		field         string
+		first, second []string
+	}{
+		{"first_name", []string{first.FirstName}, []string{second.FirstName}},
+		{"last_name", []string{first.LastName}, []string{second.LastName}},
+		{"phones", phoneLabels(first.Phones), phoneLabels(second.Phones)},
+		{"emails", emailLabels(first.Emails), emailLabels(second.Emails)},
+		{"addresses", addressLabels(first.Addresses), addressLabels(second.Addresses)},
+		{"created_on", []string{first.CreatedOn.Format(time.DateOnly)}, []string{second.CreatedOn.Format(time.DateOnly)}},
+	}
+	for _, row := range rows {
+		lines := len(row.first)
+		if len(row.second) > lines {
+			lines = len(row.second)
+		}
+		for i := 0; i < lines || i == 0; i++ {
+			field := ""
+			if i == 0 {
+				field = row.field
+			}
+			println(fmt.Sprintf("%-10s | %-*s | %s", field, columnWidth, cell(row.first, i), cell(row.second, i)))
+		}
+	}
+}
+
+// pickFields builds the merged contact, asking which value to keep for each field that differs
+func (instance *Cli) pickFields(first, second models.Contact) models.Contact {
+	bothString, _ := instance.I18n.T(constants.KeepBoth, nil)
+	merged := first
+	merged.FirstName = instance.pickValue("first_name", first.FirstName, second.FirstName)
+	merged.LastName = instance.pickValue("last_name", first.LastName, second.LastName)
+
+	phones := instance.pickList("phones", strings.Join(phoneLabels(first.Phones), ", "), strings.Join(phoneLabels(second.Phones), ", "), bothString)
+	merged.Phones = pickEntries(phones, first.Phones, second.Phones, func(phone models.Phone) string { return phone.Number })
+	emails := instance.pickList("emails", strings.Join(emailLabels(first.Emails), ", "), strings.Join(emailLabels(second.Emails), ", "), bothString)
+	merged.Emails = pickEntries(emails, first.Emails, second.Emails, func(email models.Email) string { return strings.ToLower(email.Address) })
+	addresses := instance.pickList("addresses", strings.Join(addressLabels(first.Addresses), "; "), strings.Join(addressLabels(second.Addresses), "; "), bothString)
+	merged.Addresses = pickEntries(addresses, first.Addresses, second.Addresses, func(address models.Address) string {
+		return strings.ToLower(formatAddress(address))
+	})
+
+	merged.CreatedOn = time.Time{}
+	return merged
+}
+
+// pickValue asks which of two different values to keep
+func (instance *Cli) pickValue(field, first, second string) string {
+	if first == second {
+		return first
+	}
+	pickString, _ := instance.I18n.T(constants.PickValue, map[string]interface{}{"Field": field})
+	return instance.selectOption(pickString, []string{first, second}, first)
+}
+
+// pickList asks whether to keep the entries of the first contact, of the second or of both, returning 0, 1 or 2
+func (instance *Cli) pickList(field, first, second, both string) int {
+	if first == second || second == "" {
+		return 0
+	}
+	if first == "" {
+		return 1
+	}
+	pickString, _ := instance.I18n.T(constants.PickValue, map[string]interface{}{"Field": field})
+	options := []string{first, second, both}
+	choice := instance.selectOption(pickString, options, both)
+	for i, option := range options {
+		if option == choice {
+			return i
+		}
+	}
+	return 2
+}
+
+// pickEntries returns the entries of the first list, of the second or of both without the ones having the same key
+func pickEntries[T any](choice int, first, second []T, key func(T) string) []T {
+	switch choice {
+	case 0:
+		return first
+	case 1:
+		return second
+	}
+	merged := append([]T{}, first...)
+	seen := make(map[string]bool, len(first))
+	for _, entry := range first {
+		seen[key(entry)] = true
+	}
+	for _, entry := range second {
+		if !seen[key(entry)] {
+			seen[key(entry)] = true
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+func phoneLabels(phones []models.Phone) []string {
+	labels := make([]string, 0, len(phones))
+	for _, phone := range phones {
+		labels = append(labels, fmt.Sprintf("%s (%s)", phone.Number, phone.Type))
+	}
+	return labels
+}
+
+func emailLabels(emails []models.Email) []string {
+	labels := make([]string, 0, len(emails))
+	for _, email := range emails {
+		labels = append(labels, fmt.Sprintf("%s (%s)", email.Address, email.Type))
+	}
+	return labels
+}
+
+func addressLabels(addresses []models.Address) []string {
+	labels := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		labels = append(labels, formatAddress(address))
+	}
+	return labels
+}
+
+func formatAddress(address models.Address) string {
+	var parts []string
+	for _, part := range []string{address.Street, address.City, address.State, address.Zip, address.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cell returns the line of a column, cut to the column width
+func cell(lines []string, i int) string {
+	if i >= len(lines) {
+		return ""
+	}
+	line := []rune(lines[i])
+	if len(line) > columnWidth {
+		return string(line[:columnWidth-1]) + "…"
+	}
+	return string(line)
+}
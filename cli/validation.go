@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// fieldPathRegex splits the path of an invalid field, e.g. phones[0].number, into its list, index and property
+var fieldPathRegex = regexp.MustCompile(`^(\w+)(?:\[(\d+)\])?(?:\.(\w+))?$`)
+
+// validateContact validates the contact, printing every invalid field and asking again for those fields only
+// until the contact is valid or the user gives up
+func (instance *Cli) validateContact(contact *models.Contact) bool {
+	validationString, _ := instance.I18n.T(constants.RequestValidationError, nil)
+	fixString, _ := instance.I18n.T(constants.FixInvalidFields, nil)
+	for {
+		err := utility.ValidateContact(instance.Validator, *contact)
+		if err == nil {
+			return true
+		}
+		var validationErr *utility.ValidationError
+		if !errors.As(err, &validationErr) {
+			println("failed to validate request", "err: ", err.Error())
+			println(constants.LineSeparator)
+			return false
+		}
+		validationErr.Localize(instance.I18n)
+		println(validationString)
+		for _, field := range validationErr.Fields {
+			println(" -", field.Message)
+		}
+		println(constants.LineSeparator)
+		if !instance.confirm(fixString) {
+			return false
+		}
+		instance.readInvalidFields(contact, validationErr.Fields)
+		contact.NormalizePrimary()
+	}
+}
+
+// readInvalidFields prompts again for each field that failed validation, pre-filled with its current value
+func (instance *Cli) readInvalidFields(contact *models.Contact, fields []utility.FieldError) {
+	fullNameString, _ := instance.I18n.T(constants.FullName, nil)
+	phoneNumber, _ := instance.I18n.T(constants.Phone, nil)
+	eMailAddress, _ := instance.I18n.T(constants.Email, nil)
+	typeString, _ := instance.I18n.T(constants.Type, nil)
+
+	nameRead := false
+	for _, field := range fields {
+		list, index, property := parseFieldPath(field.Field)
+		switch list {
+		case "first_name", "last_name":
+			// Both come from the full name, ask for it once
+			if nameRead {
+				continue
+			}
+			nameRead = true
+			fullName := instance.readLineWithDefault(fullNameString, contact.FirstName+" "+contact.LastName)
+			contact.FirstName, _, contact.LastName = utility.GetFirstMiddleAndLastNamesFromFullName(fullName)
+		case "phones":
+			if index < 0 || index >= len(contact.Phones) {
+				contact.Phones = instance.readPhones(contact.Phones)
+				continue
+			}
+			phone := &contact.Phones[index]
+			if property == "type" {
+				phone.Type = instance.selectOption(typeString, models.PhoneTypes, models.MobilePhone)
+			} else {
+				phone.Number = instance.readLineWithDefault(phoneNumber, phone.Number)
+			}
+		case "emails":
+			if index < 0 || index >= len(contact.Emails) {
+				contact.Emails = instance.readEmails(contact.Emails)
+				continue
+			}
+			email := &contact.Emails[index]
+			if property == "type" {
+				email.Type = instance.selectOption(typeString, models.EmailTypes, models.PersonalEmail)
+			} else {
+				email.Address = instance.readLineWithDefault(eMailAddress, email.Address)
+			}
+		case "addresses":
+			if index < 0 || index >= len(contact.Addresses) {
+				contact.Addresses = instance.readAddresses(contact.Addresses)
+				continue
+			}
+			instance.readAddressField(&contact.Addresses[index], property)
+		}
+	}
+}
+
+// readAddressField prompts again for one field of the address
+func (instance *Cli) readAddressField(address *models.Address, property string) {
+	prompts := map[string]struct {
+		key   string
+		value *string
+	}{
+		"street":  {constants.Street, &address.Street},
+		"city":    {constants.City, &address.City},
+		"state":   {constants.State, &address.State},
+		"zip":     {constants.Zip, &address.Zip},
+		"country": {constants.Country, &address.Country},
+	}
+	if property == "type" {
+		typeString, _ := instance.I18n.T(constants.Type, nil)
+		address.Type = instance.selectOption(typeString, models.AddressTypes, models.PersonalAddress)
+		return
+	}
+	prompt, found := prompts[property]
+	if !found {
+		*address = instance.readAddress(*address)
+		return
+	}
+	promptString, _ := instance.I18n.T(prompt.key, nil)
+	*prompt.value = instance.readLineWithDefault(promptString, *prompt.value)
+}
+
+// parseFieldPath splits a field path such as addresses[1].zip, the index being -1 when there is none
+func parseFieldPath(path string) (list string, index int, property string) {
+	matches := fieldPathRegex.FindStringSubmatch(path)
+	if matches == nil {
+		return path, -1, ""
+	}
+	index = -1
+	if matches[2] != "" {
+		index, _ = strconv.Atoi(matches[2])
+	}
+	return matches[1], index, matches[3]
+}
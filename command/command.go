@@ -0,0 +1,154 @@
+// Package command runs the address book non-interactively, one subcommand per invocation, for shell scripts and cron:
+//
+//	GoAddressBook add --name "Naruto Uzumaki" --phone mobile:+919876543210 --email naruto@konoha.in \
+//		--address "professional:Hokage Tower;Konoha;;560001;India"
+//	GoAddressBook search --phone 98765-43210
+//
+// The exit code tells scripts whether the subcommand succeeded, the input was invalid or nothing was found.
+package command
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/i18n"
+	"GoAddressBook/utility"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+)
+
+// Exit codes of the subcommands
+const (
+	ExitSuccess  = 0
+	ExitFailure  = 1 // the book couldn't be read or saved
+	ExitUsage    = 2 // unknown subcommand, flag or argument
+	ExitInvalid  = 3 // a contact failed validation
+	ExitNotFound = 4 // no contact has the given ID or matches the search
+)
+
+// Command runs the subcommands against an address book
+type Command struct {
+	Book      *addressbook.AddressBook
+	I18n      *i18n.Internationalization
+	Validator *validator.Validate
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+}
+
+type subcommand struct {
+	name        string
+	usage       string
+	description string
+	run         func(c *Command, args []string) int
+}
+
+// subcommands lists the subcommands in the order of the usage
+var subcommands = []subcommand{
+	{"add", "add [contact flags]", "add a contact and print its ID", (*Command).add},
+	{"get", "get [--output format] <id>", "print a contact", (*Command).get},
+	{"search", "search [--output format] [--name name | --phone number | --query query | text]", "print the matching contacts", (*Command).search},
+	{"list", "list [--output format] [--sort field] [--desc] [--limit n] [--cursor cursor]", "print the contacts sorted by a field", (*Command).list},
+	{"update", "update <id> [contact flags]", "change the given fields of a contact", (*Command).update},
+	{"delete", "delete <id>", "delete a contact", (*Command).delete},
+	{"import", "import [--format vcard|csv|ldif] <file>", "add the contacts of a file, - for the standard input", (*Command).importFile},
+	{"export", "export [--format vcard|csv|ldif] [--file path] [id...]", "write the given contacts, or all of them", (*Command).exportFile},
+	{"serve", "serve [--bind address] [--port n]", "serve the REST API and CardDAV until interrupted", (*Command).serve},
+	{"serve-grpc", "serve-grpc [--bind address] [--port n]", "serve the gRPC service until interrupted", (*Command).serveGrpc},
+}
+
+// NewCommand returns a Command reading and writing the standard streams
+func NewCommand(book *addressbook.AddressBook) (*Command, error) {
+	i18nInstance, err := i18n.NewI18nInstance(viper.GetString(constants.Locale))
+	if err != nil {
+		return nil, err
+	}
+	return &Command{
+		Book:      book,
+		I18n:      &i18nInstance,
+		Validator: utility.NewValidator(),
+		Stdin:     os.Stdin,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}, nil
+}
+
+// Run runs the subcommand named by the first argument and returns the exit code
+func (c *Command) Run(args []string) int {
+	if len(args) == 0 {
+		c.usage()
+		return ExitUsage
+	}
+	for _, sub := range subcommands {
+		if sub.name == args[0] {
+			return sub.run(c, args[1:])
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.usage()
+		return ExitSuccess
+	}
+	c.messagef("unknown subcommand %q", args[0])
+	c.usage()
+	return ExitUsage
+}
+
+func (c *Command) usage() {
+	fmt.Fprintln(c.Stderr, "Usage: GoAddressBook [subcommand]")
+	fmt.Fprintln(c.Stderr, "Without subcommand, the interactive menu is opened.")
+	fmt.Fprintln(c.Stderr)
+	fmt.Fprintln(c.Stderr, "Subcommands:")
+	for _, sub := range subcommands {
+		fmt.Fprintf(c.Stderr, "  %s\n    \t%s\n", sub.usage, sub.description)
+	}
+	fmt.Fprintln(c.Stderr)
+	fmt.Fprintln(c.Stderr, "Contact flags:")
+	contactFlagSet := c.newFlagSet("")
+	newContactFlags(contactFlagSet)
+	contactFlagSet.PrintDefaults()
+	fmt.Fprintln(c.Stderr)
+	fmt.Fprintf(c.Stderr, "Exit codes: %d success, %d failure, %d usage, %d invalid contact, %d not found\n",
+		ExitSuccess, ExitFailure, ExitUsage, ExitInvalid, ExitNotFound)
+}
+
+// newFlagSet returns the flags of a subcommand, reporting their errors on the standard error
+func (c *Command) newFlagSet(name string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(c.Stderr)
+	return flagSet
+}
+
+// messagef prints a message on the standard error, keeping the standard output for the data scripts read
+func (c *Command) messagef(format string, args ...interface{}) {
+	fmt.Fprintf(c.Stderr, format+"\n", args...)
+}
+
+// validationFailed prints every invalid field of the contact and returns ExitInvalid, or ExitFailure for other errors
+func (c *Command) validationFailed(err error) int {
+	var validationErr *utility.ValidationError
+	if !errors.As(err, &validationErr) {
+		c.messagef("failed to validate contact: %s", err)
+		return ExitFailure
+	}
+	validationErr.Localize(c.I18n)
+	for _, field := range validationErr.Fields {
+		c.messagef("%s: %s", field.Field, field.Message)
+	}
+	return ExitInvalid
+}
+
+// bookFailed reports an error of the address book, with the exit code matching it
+func (c *Command) bookFailed(action string, err error) int {
+	c.messagef("failed to %s contact: %s", action, err)
+	switch {
+	case errors.Is(err, addressbook.ContactNotFound):
+		return ExitNotFound
+	case errors.Is(err, addressbook.ContactAlreadyExists):
+		return ExitInvalid
+	}
+	return ExitFailure
+}
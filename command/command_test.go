@@ -0,0 +1,184 @@
+package command
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/i18n"
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"GoAddressBook/utility"
+	"bytes"
+	"flag"
+	"github.com/BurntSushi/toml"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nameTemplate = "template={{.FirstName}} {{.LastName}}"
+
+// newTestCommand returns a command on a book holding Ada and Alan, with buffers as standard output and error
+func newTestCommand(t *testing.T) (*Command, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	bundle := goi18n.NewBundle(language.English)
+	bundle.RegisterUnmarshalFunc(constants.TomlFileFormat, toml.Unmarshal)
+	if _, err := bundle.LoadMessageFile(filepath.Join("..", constants.EnTomlFilePath)); err != nil {
+		t.Fatal(err)
+	}
+	book := addressbook.NewAddressBook(repository.NewMemoryRepository())
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, contact := range []models.Contact{
+		{ID: "ada", FirstName: "Ada", LastName: "Lovelace", CreatedOn: created, ModifiedOn: created,
+			Phones: []models.Phone{{Type: models.MobilePhone, Number: "+919876543210"}},
+			Emails: []models.Email{{Type: models.PersonalEmail, Address: "ada@example.com"}}},
+		{ID: "alan", FirstName: "Alan", LastName: "Turing", CreatedOn: created, ModifiedOn: created,
+			Phones: []models.Phone{{Type: models.WorkPhone, Number: "+919876543211"}},
+			Emails: []models.Email{{Type: models.WorkEmail, Address: "alan@example.com"}}},
+	} {
+		if _, err := book.AddContact(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	return &Command{
+		Book:      book,
+		I18n:      &i18n.Internationalization{Localizer: goi18n.NewLocalizer(bundle, "en")},
+		Validator: utility.NewValidator(),
+		Stdin:     strings.NewReader(""),
+		Stdout:    &stdout,
+		Stderr:    &stderr,
+	}, &stdout, &stderr
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		code         int
+		stdout       string
+		stderr       string
+		stderrPrefix bool // stderr only starts with the expected one, such as a usage
+	}{
+		{name: "no subcommand", code: ExitUsage, stderr: "Usage: GoAddressBook [subcommand]\n", stderrPrefix: true},
+		{name: "help", args: []string{"help"}, code: ExitSuccess, stderr: "Usage: GoAddressBook [subcommand]\n", stderrPrefix: true},
+		{name: "unknown subcommand", args: []string{"frobnicate"}, code: ExitUsage,
+			stderr: "unknown subcommand \"frobnicate\"\nUsage: GoAddressBook [subcommand]\n", stderrPrefix: true},
+		{name: "get", args: []string{"get", "--output", nameTemplate, "ada"}, code: ExitSuccess, stdout: "Ada Lovelace\n"},
+		{name: "flags after the ID", args: []string{"get", "ada", "--output", nameTemplate}, code: ExitSuccess, stdout: "Ada Lovelace\n"},
+		{name: "unknown ID", args: []string{"get", "grace"}, code: ExitNotFound, stderr: "contact grace not found\n"},
+		{name: "flag-like ID after --", args: []string{"get", "--", "--ada"}, code: ExitNotFound, stderr: "contact --ada not found\n"},
+		{name: "missing ID", args: []string{"get"}, code: ExitUsage, stderr: "get needs exactly one contact ID\n"},
+		{name: "several IDs", args: []string{"delete", "ada", "alan"}, code: ExitUsage, stderr: "delete needs exactly one contact ID\n"},
+		{name: "unknown flag", args: []string{"get", "--nope", "ada"}, code: ExitUsage,
+			stderr: "flag provided but not defined: -nope\nUsage of get:\n", stderrPrefix: true},
+		{name: "search by phone", args: []string{"search", "--phone", "+91 98765 43211", "--output", nameTemplate},
+			code: ExitSuccess, stdout: "Alan Turing\n"},
+		{name: "search without match", args: []string{"search", "--phone", "+91 98765 43212"}, code: ExitNotFound},
+		{name: "search without criteria", args: []string{"search"}, code: ExitUsage,
+			stderr: "search needs --name, --phone, --query or a text to search\n"},
+		{name: "invalid query", args: []string{"search", "--query", "city ="}, code: ExitUsage, stderr: "invalid query: ", stderrPrefix: true},
+		{name: "list", args: []string{"list", "--sort", "name", "--desc", "--output", nameTemplate}, code: ExitSuccess,
+			stdout: "Alan Turing\nAda Lovelace\n"},
+		{name: "list a page", args: []string{"list", "--limit", "1", "--output", nameTemplate}, code: ExitSuccess,
+			stdout: "Ada Lovelace\n", stderr: "next cursor: ", stderrPrefix: true},
+		{name: "add with an invalid phone", code: ExitInvalid,
+			args:   []string{"add", "--name", "Grace Hopper", "--phone", "12", "--email", "grace@example.com"},
+			stderr: "phones[0].number: Le numéro de téléphone « 12 » de phones[0].number n'est pas valide\n"},
+		{name: "add with a taken phone", code: ExitInvalid,
+			args:   []string{"add", "--name", "Grace Hopper", "--phone", "+919876543210", "--email", "grace@example.com"},
+			stderr: "failed to add contact: " + addressbook.ContactAlreadyExists.Error() + "\n"},
+		{name: "add with an argument", args: []string{"add", "--name", "Grace Hopper", "grace"}, code: ExitUsage,
+			stderr: "unexpected argument \"grace\"\n"},
+		{name: "update", args: []string{"update", "ada", "--last-name", "King"}, code: ExitSuccess},
+		{name: "update unknown ID", args: []string{"update", "grace", "--last-name", "Hopper"}, code: ExitNotFound,
+			stderr: "contact grace not found\n"},
+		{name: "delete", args: []string{"delete", "alan"}, code: ExitSuccess},
+		{name: "delete unknown ID", args: []string{"delete", "grace"}, code: ExitNotFound,
+			stderr: "failed to delete contact: " + addressbook.ContactNotFound.Error() + "\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, stdout, stderr := newTestCommand(t)
+			if code := command.Run(test.args); code != test.code {
+				t.Errorf("exit code = %d, want %d", code, test.code)
+			}
+			if stdout.String() != test.stdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), test.stdout)
+			}
+			if test.stderrPrefix && !strings.HasPrefix(stderr.String(), test.stderr) ||
+				!test.stderrPrefix && stderr.String() != test.stderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), test.stderr)
+			}
+		})
+	}
+}
+
+func TestContactFlags(t *testing.T) {
+	command, stdout, stderr := newTestCommand(t)
+	code := command.Run([]string{"add", "--name", "Grace Hopper",
+		"--phone", "mobile:+919876543212", "--phone", "work:+919876543213",
+		"--email", "grace@example.com", "--email", "work:hopper@navy.mil",
+		"--address", "12 MG Road;Bengaluru;Karnataka;560001;India",
+		"--address", "professional: 1 Navy Yard ; Washington ; DC ; 20374 ; USA",
+		"--city", "Bangalore"})
+	if code != ExitSuccess {
+		t.Fatalf("exit code = %d, stderr %q", code, stderr.String())
+	}
+	contact, found := command.Book.GetContact(strings.TrimSpace(stdout.String()))
+	if !found {
+		t.Fatalf("no contact with the printed ID %q", stdout.String())
+	}
+
+	phones := []models.Phone{
+		{Type: models.MobilePhone, Number: "+919876543212", Primary: true},
+		{Type: models.WorkPhone, Number: "+919876543213"},
+	}
+	emails := []models.Email{
+		{Type: models.PersonalEmail, Address: "grace@example.com", Primary: true},
+		{Type: models.WorkEmail, Address: "hopper@navy.mil"},
+	}
+	addresses := []models.Address{
+		{Type: models.PersonalAddress, Street: "12 MG Road", City: "Bangalore", State: "Karnataka", Zip: "560001", Country: "India", Primary: true},
+		{Type: models.ProfessionalAddress, Street: "1 Navy Yard", City: "Washington", State: "DC", Zip: "20374", Country: "USA"},
+	}
+	if !reflect.DeepEqual(contact.Phones, phones) {
+		t.Errorf("phones = %+v, want %+v", contact.Phones, phones)
+	}
+	if !reflect.DeepEqual(contact.Emails, emails) {
+		t.Errorf("emails = %+v, want %+v", contact.Emails, emails)
+	}
+	if !reflect.DeepEqual(contact.Addresses, addresses) {
+		t.Errorf("addresses = %+v, want %+v", contact.Addresses, addresses)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		positional []string
+		name       string
+	}{
+		{args: []string{"a", "--name", "Ada", "b"}, positional: []string{"a", "b"}, name: "Ada"},
+		{args: []string{"--", "--name", "Ada"}, positional: []string{"--name", "Ada"}},
+		{args: []string{"a", "--desc", "--", "-b", "--name", "Ada"}, positional: []string{"a", "-b", "--name", "Ada"}},
+		{args: []string{"--name", "--", "a"}, positional: []string{"a"}, name: "--"},
+		{args: []string{"--name=--", "--", "--desc"}, positional: []string{"--desc"}, name: "--"},
+	}
+	for _, test := range tests {
+		flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+		name := flagSet.String("name", "", "")
+		flagSet.Bool("desc", false, "")
+		positional, err := parseArgs(flagSet, test.args)
+		if err != nil {
+			t.Errorf("parseArgs(%q): %v", test.args, err)
+			continue
+		}
+		if strings.Join(positional, " ") != strings.Join(test.positional, " ") || *name != test.name {
+			t.Errorf("parseArgs(%q) = %q with name %q, want %q with name %q", test.args, positional, *name, test.positional, test.name)
+		}
+	}
+}
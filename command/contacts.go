@@ -0,0 +1,207 @@
+package command
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"GoAddressBook/output"
+	"GoAddressBook/utility"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+// add validates and adds a contact made of the contact flags, printing its ID
+func (c *Command) add(args []string) int {
+	flagSet := c.newFlagSet("add")
+	fields := newContactFlags(flagSet)
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		c.messagef("unexpected argument %q", positional[0])
+		return ExitUsage
+	}
+
+	contact := models.Contact{CreatedOn: time.Now()}
+	fields.apply(&contact)
+	if err := utility.ValidateContact(c.Validator, contact); err != nil {
+		return c.validationFailed(err)
+	}
+	id, err := c.Book.AddContact(contact)
+	if err != nil {
+		return c.bookFailed("add", err)
+	}
+	fmt.Fprintln(c.Stdout, id)
+	return ExitSuccess
+}
+
+// get prints the contact with the given ID
+func (c *Command) get(args []string) int {
+	flagSet := c.newFlagSet("get")
+	format := newFormatFlag(flagSet)
+	id, code := c.parseID(flagSet, args)
+	if code != ExitSuccess {
+		return code
+	}
+	contact, found := c.Book.GetContact(id)
+	if !found {
+		c.messagef("contact %s not found", id)
+		return ExitNotFound
+	}
+	return c.printContacts(format.format, []models.Contact{contact})
+}
+
+// update changes the fields of a contact given by the contact flags
+func (c *Command) update(args []string) int {
+	flagSet := c.newFlagSet("update")
+	fields := newContactFlags(flagSet)
+	id, code := c.parseID(flagSet, args)
+	if code != ExitSuccess {
+		return code
+	}
+	contact, found := c.Book.GetContact(id)
+	if !found {
+		c.messagef("contact %s not found", id)
+		return ExitNotFound
+	}
+
+	fields.apply(&contact)
+	if err := utility.ValidateContact(c.Validator, contact); err != nil {
+		return c.validationFailed(err)
+	}
+	if err := c.Book.UpdateContact(id, contact); err != nil {
+		return c.bookFailed("update", err)
+	}
+	return ExitSuccess
+}
+
+// delete removes the contact with the given ID
+func (c *Command) delete(args []string) int {
+	id, code := c.parseID(c.newFlagSet("delete"), args)
+	if code != ExitSuccess {
+		return code
+	}
+	if _, err := c.Book.DeleteContact(id); err != nil {
+		return c.bookFailed("delete", err)
+	}
+	return ExitSuccess
+}
+
+// search prints the contacts matching a name, a phone number, a structured query or a full-text search
+func (c *Command) search(args []string) int {
+	flagSet := c.newFlagSet("search")
+	name := flagSet.String("name", "", "search by `name`, exactly, by prefix or approximately")
+	phone := flagSet.String("phone", "", "search by phone `number`, in any format")
+	where := flagSet.String("query", "", "structured `query`, e.g. state = \"karnataka\" ORDER BY last_name")
+	format := newFormatFlag(flagSet)
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+
+	var contacts []models.Contact
+	switch {
+	case *name != "":
+		matches := c.Book.SearchNames(*name, addressbook.NameSearchOptions{
+			Prefix:      true,
+			MaxDistance: viper.GetInt(constants.SearchMaxDistance),
+			Limit:       viper.GetInt(constants.SearchLimit),
+			Phonetic:    viper.GetBool(constants.SearchPhonetic),
+		})
+		for _, match := range matches {
+			contacts = append(contacts, match.Contact)
+		}
+	case *phone != "":
+		if contact, found := c.Book.SearchByPhoneNumber(*phone); found {
+			contacts = append(contacts, contact)
+		}
+	case *where != "":
+		if contacts, err = c.Book.Query(*where); err != nil {
+			c.messagef("invalid query: %s", err)
+			return ExitUsage
+		}
+	case len(positional) > 0:
+		if contacts, err = c.Book.Search(strings.Join(positional, " ")); err != nil {
+			c.messagef("invalid search: %s", err)
+			return ExitUsage
+		}
+	default:
+		c.messagef("search needs --name, --phone, --query or a text to search")
+		return ExitUsage
+	}
+
+	if len(contacts) == 0 {
+		return ExitNotFound
+	}
+	return c.printContacts(format.format, contacts)
+}
+
+// list prints the contacts sorted by a field, all of them or one page when a limit is given
+func (c *Command) list(args []string) int {
+	flagSet := c.newFlagSet("list")
+	sortBy := flagSet.String("sort", addressbook.SortByName, "`field` to sort by: name, created_on or city")
+	descending := flagSet.Bool("desc", false, "sort in descending order")
+	limit := flagSet.Int("limit", 0, "print at most `n` contacts and the cursor of the next page, 0 for all")
+	pageCursor := flagSet.String("cursor", "", "`cursor` of the page to print, as printed by the previous one")
+	format := newFormatFlag(flagSet)
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		c.messagef("unexpected argument %q", positional[0])
+		return ExitUsage
+	}
+
+	options := addressbook.ListOptions{SortBy: *sortBy, Descending: *descending, Limit: *limit, Cursor: *pageCursor}
+	var contacts []models.Contact
+	for {
+		page, err := c.Book.List(options)
+		if err != nil {
+			c.messagef("failed to list contacts: %s", err)
+			return ExitUsage
+		}
+		contacts = append(contacts, page.Contacts...)
+		if *limit > 0 {
+			if page.NextCursor != "" {
+				c.messagef("next cursor: %s", page.NextCursor)
+			}
+			break
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		options.Cursor = page.NextCursor
+	}
+	return c.printContacts(format.format, contacts)
+}
+
+// parseID parses the flags of a subcommand taking exactly one contact ID
+func (c *Command) parseID(flagSet *flag.FlagSet, args []string) (string, int) {
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return "", ExitUsage
+	}
+	if len(positional) != 1 {
+		c.messagef("%s needs exactly one contact ID", flagSet.Name())
+		return "", ExitUsage
+	}
+	return positional[0], ExitSuccess
+}
+
+// printContacts prints the contacts in the output format
+func (c *Command) printContacts(format output.Format, contacts []models.Contact) int {
+	if err := format.Write(c.Stdout, contacts); err != nil {
+		c.messagef("failed to print contacts: %s", err)
+		if errors.Is(err, output.InvalidTemplate) {
+			return ExitUsage
+		}
+		return ExitFailure
+	}
+	return ExitSuccess
+}
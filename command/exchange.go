@@ -0,0 +1,161 @@
+package command
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/csvcontacts"
+	"GoAddressBook/ldif"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"GoAddressBook/vcard"
+	"errors"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the import and export subcommands
+const (
+	VCardFormat = "vcard"
+	CSVFormat   = "csv"
+	LDIFFormat  = "ldif"
+)
+
+// formatExtensions maps the file extensions to their format, when --format isn't given
+var formatExtensions = map[string]string{
+	".vcf":   VCardFormat,
+	".vcard": VCardFormat,
+	".csv":   CSVFormat,
+	".ldif":  LDIFFormat,
+	".ldf":   LDIFFormat,
+}
+
+// importFile adds the contacts of a file, reporting the rejected ones. It fails with ExitInvalid when any was rejected.
+func (c *Command) importFile(args []string) int {
+	flagSet := c.newFlagSet("import")
+	format := flagSet.String("format", "", "`format` of the file: vcard, csv or ldif, guessed from its extension by default")
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		c.messagef("import needs exactly one file, - for the standard input")
+		return ExitUsage
+	}
+	path := positional[0]
+	if *format == "" {
+		*format = formatExtensions[strings.ToLower(filepath.Ext(path))]
+	}
+
+	var decode func(r io.Reader) ([]models.Contact, error)
+	switch *format {
+	case VCardFormat:
+		decode = vcard.Decode
+	case CSVFormat:
+		decode = csvcontacts.Decode
+	case LDIFFormat:
+		decode = ldif.Decode
+	default:
+		c.messagef("unknown import format %q, use --format vcard, csv or ldif", *format)
+		return ExitUsage
+	}
+
+	reader := c.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			c.messagef("failed to open file: %s", err)
+			return ExitFailure
+		}
+		defer file.Close()
+		reader = file
+	}
+	contacts, err := decode(reader)
+	if err != nil {
+		c.messagef("failed to read contacts: %s", err)
+		return ExitInvalid
+	}
+
+	added, failures := c.Book.Import(contacts, c.Validator)
+	for _, failure := range failures {
+		var validationErr *utility.ValidationError
+		if errors.As(failure.Err, &validationErr) {
+			validationErr.Localize(c.I18n)
+		}
+		c.messagef("#%d %s %s: %s", failure.Index, failure.Contact.FirstName, failure.Contact.LastName, failure.Err)
+	}
+	c.messagef("%d contacts imported, %d rejected", added, len(failures))
+	if len(failures) > 0 {
+		return ExitInvalid
+	}
+	return ExitSuccess
+}
+
+// exportFile writes the contacts with the given IDs, or the whole book, to the standard output or a file
+func (c *Command) exportFile(args []string) int {
+	flagSet := c.newFlagSet("export")
+	format := flagSet.String("format", "", "`format` of the file: vcard, csv or ldif, guessed from --file or vcard by default")
+	path := flagSet.String("file", "", "`path` of the file to write, the standard output by default")
+	version := flagSet.String("version", vcard.Version4, "vCard `version`: "+strings.Join(vcard.Versions, " or "))
+	baseDN := flagSet.String("base-dn", viper.GetString(constants.LdifBaseDN), "LDIF `DN` the entries are written under")
+	ids, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if *format == "" {
+		*format = formatExtensions[strings.ToLower(filepath.Ext(*path))]
+	}
+	if *format == "" {
+		*format = VCardFormat
+	}
+
+	contacts := c.Book.AllContacts()
+	if len(ids) > 0 {
+		contacts = make([]models.Contact, 0, len(ids))
+		for _, id := range ids {
+			contact, found := c.Book.GetContact(id)
+			if !found {
+				c.messagef("contact %s not found", id)
+				return ExitNotFound
+			}
+			contacts = append(contacts, contact)
+		}
+	}
+
+	var encode func(w io.Writer) error
+	switch *format {
+	case VCardFormat:
+		encode = func(w io.Writer) error { return vcard.Encode(w, contacts, *version) }
+	case CSVFormat:
+		encode = func(w io.Writer) error { return csvcontacts.Encode(w, contacts) }
+	case LDIFFormat:
+		encode = func(w io.Writer) error { return ldif.Encode(w, contacts, *baseDN) }
+	default:
+		c.messagef("unknown export format %q, use --format vcard, csv or ldif", *format)
+		return ExitUsage
+	}
+
+	if *path == "" {
+		if err := encode(c.Stdout); err != nil {
+			c.messagef("failed to write contacts: %s", err)
+			return ExitFailure
+		}
+		return ExitSuccess
+	}
+	file, err := os.Create(*path)
+	if err != nil {
+		c.messagef("failed to create file: %s", err)
+		return ExitFailure
+	}
+	err = encode(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		c.messagef("failed to write contacts: %s", err)
+		return ExitFailure
+	}
+	c.messagef("%d contacts exported to %s", len(contacts), *path)
+	return ExitSuccess
+}
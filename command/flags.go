@@ -0,0 +1,230 @@
+package command
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"GoAddressBook/output"
+	"GoAddressBook/utility"
+	"flag"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// stringList is a flag that can be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// formatFlag is the --output flag, checked while the flags are parsed
+type formatFlag struct {
+	spec   string
+	format output.Format
+}
+
+// newFormatFlag registers the --output flag, defaulting to the configured output format
+func newFormatFlag(flagSet *flag.FlagSet) *formatFlag {
+	f := &formatFlag{spec: output.Table, format: output.Format{Name: output.Table}}
+	if configured := viper.GetString(constants.OutputFormat); configured != "" {
+		_ = f.Set(configured)
+	}
+	flagSet.Var(f, "output", "output `format`: table, json, jsonl, yaml, csv or template=<go-template>")
+	return f
+}
+
+func (f *formatFlag) String() string {
+	return f.spec
+}
+
+func (f *formatFlag) Set(value string) error {
+	format, err := output.Parse(value)
+	if err != nil {
+		return err
+	}
+	f.spec, f.format = value, format
+	return nil
+}
+
+// contactFlags are the flags setting the fields of a contact
+type contactFlags struct {
+	flagSet     *flag.FlagSet
+	name        string
+	firstName   string
+	lastName    string
+	phones      stringList
+	emails      stringList
+	addresses   stringList
+	addressType string
+	street      string
+	city        string
+	state       string
+	zip         string
+	country     string
+}
+
+// newContactFlags registers the contact flags on the flag set
+func newContactFlags(flagSet *flag.FlagSet) *contactFlags {
+	f := &contactFlags{flagSet: flagSet}
+	flagSet.StringVar(&f.name, "name", "", "full `name`, split into first and last name")
+	flagSet.StringVar(&f.firstName, "first-name", "", "first `name`")
+	flagSet.StringVar(&f.lastName, "last-name", "", "last `name`")
+	flagSet.Var(&f.phones, "phone", "phone `number`, optionally prefixed by its type and a colon ("+strings.Join(models.PhoneTypes, ", ")+"), repeat for several phones")
+	flagSet.Var(&f.emails, "email", "email `address`, optionally prefixed by its type and a colon ("+strings.Join(models.EmailTypes, ", ")+"), repeat for several emails")
+	flagSet.Var(&f.addresses, "address", "`address` as street;city;state;zip;country, optionally prefixed by its type and a colon ("+strings.Join(models.AddressTypes, ", ")+"), repeat for several addresses")
+	flagSet.StringVar(&f.addressType, "address-type", "", "`type` of the primary address: "+strings.Join(models.AddressTypes, ", "))
+	flagSet.StringVar(&f.street, "street", "", "`street` of the primary address")
+	flagSet.StringVar(&f.city, "city", "", "`city` of the primary address")
+	flagSet.StringVar(&f.state, "state", "", "`state` of the primary address")
+	flagSet.StringVar(&f.zip, "zip", "", "postal `code` of the primary address")
+	flagSet.StringVar(&f.country, "country", "", "`country` of the primary address")
+	return f
+}
+
+// apply sets the fields of the contact given on the command line, leaving the others unchanged.
+// Phones, emails and addresses replace the existing ones, address fields change the primary address.
+func (f *contactFlags) apply(contact *models.Contact) {
+	set := make(map[string]bool)
+	f.flagSet.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+
+	if set["name"] {
+		contact.FirstName, _, contact.LastName = utility.GetFirstMiddleAndLastNamesFromFullName(f.name)
+	}
+	if set["first-name"] {
+		contact.FirstName = f.firstName
+	}
+	if set["last-name"] {
+		contact.LastName = f.lastName
+	}
+	if set["phone"] {
+		contact.Phones = make([]models.Phone, 0, len(f.phones))
+		for _, value := range f.phones {
+			phoneType, number := splitType(value, models.PhoneTypes, models.MobilePhone)
+			contact.Phones = append(contact.Phones, models.Phone{Type: phoneType, Number: number})
+		}
+	}
+	if set["email"] {
+		contact.Emails = make([]models.Email, 0, len(f.emails))
+		for _, value := range f.emails {
+			emailType, address := splitType(value, models.EmailTypes, models.PersonalEmail)
+			contact.Emails = append(contact.Emails, models.Email{Type: emailType, Address: address})
+		}
+	}
+	if set["address"] {
+		contact.Addresses = make([]models.Address, 0, len(f.addresses))
+		for _, value := range f.addresses {
+			contact.Addresses = append(contact.Addresses, parseAddress(value))
+		}
+	}
+
+	if set["address-type"] || set["street"] || set["city"] || set["state"] || set["zip"] || set["country"] {
+		index := primaryAddressIndex(contact.Addresses)
+		if index < 0 {
+			contact.Addresses = append(contact.Addresses, models.Address{Type: models.PersonalAddress, Primary: true})
+			index = len(contact.Addresses) - 1
+		}
+		address := &contact.Addresses[index]
+		for name, field := range map[string]struct {
+			value       string
+			destination *string
+		}{
+			"address-type": {f.addressType, &address.Type},
+			"street":       {f.street, &address.Street},
+			"city":         {f.city, &address.City},
+			"state":        {f.state, &address.State},
+			"zip":          {f.zip, &address.Zip},
+			"country":      {f.country, &address.Country},
+		} {
+			if set[name] {
+				*field.destination = field.value
+			}
+		}
+	}
+	contact.NormalizePrimary()
+}
+
+// splitType splits a "type:value" flag, the type being optional
+func splitType(value string, types []string, defaultType string) (string, string) {
+	if prefix, rest, found := strings.Cut(value, ":"); found {
+		for _, known := range types {
+			if strings.EqualFold(prefix, known) {
+				return known, strings.TrimSpace(rest)
+			}
+		}
+	}
+	return defaultType, strings.TrimSpace(value)
+}
+
+// parseAddress reads an --address flag, its fields being separated by semicolons as in the ADR of vCards
+func parseAddress(value string) models.Address {
+	addressType, fields := splitType(value, models.AddressTypes, models.PersonalAddress)
+	parts := make([]string, 5)
+	for i, part := range strings.SplitN(fields, ";", len(parts)) {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return models.Address{Type: addressType, Street: parts[0], City: parts[1], State: parts[2], Zip: parts[3], Country: parts[4]}
+}
+
+// primaryAddressIndex returns the index of the primary address, the first one when none is flagged, -1 without address
+func primaryAddressIndex(addresses []models.Address) int {
+	for i, address := range addresses {
+		if address.Primary {
+			return i
+		}
+	}
+	if len(addresses) > 0 {
+		return 0
+	}
+	return -1
+}
+
+// parseArgs parses the flags wherever they are among the positional arguments, which are returned.
+// The arguments following a "--" are all positional.
+func parseArgs(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flagSet.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// Parse consumes the "--" ending the flags, which only differs from stopping at a positional argument
+		// by the argument before the rest
+		if parsed := args[:len(args)-len(rest)]; endsWithTerminator(flagSet, parsed) {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// endsWithTerminator tells whether the parsed arguments end with a "--" ending the flags, rather than being the value
+// of the flag before it
+func endsWithTerminator(flagSet *flag.FlagSet, parsed []string) bool {
+	if len(parsed) == 0 || parsed[len(parsed)-1] != "--" {
+		return false
+	}
+	if len(parsed) == 1 {
+		return true
+	}
+	previous := parsed[len(parsed)-2]
+	name := strings.TrimLeft(previous, "-")
+	if !strings.HasPrefix(previous, "-") || strings.Contains(name, "=") {
+		return true
+	}
+	fl := flagSet.Lookup(name)
+	if fl == nil {
+		return true
+	}
+	boolFlag, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/grpcserver"
+	"GoAddressBook/server"
+	"context"
+	"github.com/spf13/viper"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// serve serves the REST API and the CardDAV endpoint over the book until interrupted
+func (c *Command) serve(args []string) int {
+	flagSet := c.newFlagSet("serve")
+	bind := flagSet.String("bind", viper.GetString(constants.ServerBind), "`address` to listen on")
+	port := flagSet.Int("port", viper.GetInt(constants.ServerPort), "`port` to listen on")
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		c.messagef("unexpected argument %q", positional[0])
+		return ExitUsage
+	}
+	viper.Set(constants.ServerBind, *bind)
+	viper.Set(constants.ServerPort, *port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.NewServer(c.Book).ListenAndServe(ctx); err != nil {
+		c.messagef("failed to serve: %s", err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// serveGrpc serves the gRPC service over the book until interrupted
+func (c *Command) serveGrpc(args []string) int {
+	flagSet := c.newFlagSet("serve-grpc")
+	bind := flagSet.String("bind", viper.GetString(constants.GrpcBind), "`address` to listen on")
+	port := flagSet.Int("port", viper.GetInt(constants.GrpcPort), "`port` to listen on")
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		c.messagef("unexpected argument %q", positional[0])
+		return ExitUsage
+	}
+	viper.Set(constants.GrpcBind, *bind)
+	viper.Set(constants.GrpcPort, *port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := grpcserver.NewServer(c.Book).ListenAndServe(ctx); err != nil {
+		c.messagef("failed to serve: %s", err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
@@ -7,6 +7,30 @@ const (
 	Service               = "go_address_book"
 	Locale                = "locale"
 	AddressBookFilePath   = "repository/address-book.json"
+	StorageType           = "storage.type"
+	StoragePath           = "storage.path"
+	StorageImportPath     = "storage.import_path"
+	JsonStorage           = "json"
+	MemoryStorage         = "memory"
+	SqliteStorage         = "sqlite"
+	SqliteFilePath        = "repository/address-book.db"
+	WalStorage            = "wal"
+	StorageWalMaxSize     = "storage.wal_max_size"
+	WalFileSuffix         = ".wal"
+	DefaultWalMaxSize     = 1 << 20
+	SearchMaxDistance     = "search.max_distance"
+	SearchLimit           = "search.limit"
+	SearchPhonetic        = "search.phonetic"
+	ListPageSize          = "list.page_size"
+	LdifBaseDN            = "ldif.base_dn"
+	DuplicateThreshold    = "duplicates.threshold"
+	PhoneDefaultRegion    = "phone.default_region"
+	AddressDefaultCountry = "address.default_country"
+	OutputFormat          = "output.format"
+	ServerBind            = "server.bind"
+	ServerPort            = "server.port"
+	GrpcBind              = "grpc.bind"
+	GrpcPort              = "grpc.port"
 	EnTomlFilePath        = "i18n/cli/en.toml"
 	FrTomlFilePath        = "i18n/cli/fr.toml"
 	TomlFileFormat        = "toml"
@@ -15,8 +39,35 @@ const (
 	Actions                = "Actions"
 	List                   = "List"
 	Create                 = "Create"
+	Update                 = "Update"
+	Delete                 = "Delete"
+	Cancel                 = "Cancel"
 	SearchByPhoneNumber    = "SearchByPhoneNumber"
 	SearchByName           = "SearchByName"
+	Search                 = "Search"
+	SearchQuery            = "SearchQuery"
+	Query                  = "Query"
+	SortBy                 = "SortBy"
+	NextPage               = "NextPage"
+	ImportVCard            = "ImportVCard"
+	ExportVCard            = "ExportVCard"
+	ImportCSV              = "ImportCSV"
+	ExportCSV              = "ExportCSV"
+	ImportLDIF             = "ImportLDIF"
+	ExportLDIF             = "ExportLDIF"
+	FindDuplicates         = "FindDuplicates"
+	NoDuplicates           = "NoDuplicates"
+	MergeContacts          = "MergeContacts"
+	PickValue              = "PickValue"
+	KeepBoth               = "KeepBoth"
+	ContactsMerged         = "ContactsMerged"
+	VCardVersion           = "VCardVersion"
+	FilePath               = "FilePath"
+	ExportAll              = "ExportAll"
+	ContactExporting       = "ContactExporting"
+	ContactsImported       = "ContactsImported"
+	ContactsExported       = "ContactsExported"
+	QueryPrompt            = "QueryPrompt"
 	Close                  = "Close"
 	UnknownChoice          = "UnknownChoice"
 	Closing                = "Closing"
@@ -29,20 +80,38 @@ const (
 	Email                  = "Email"
 	Address                = "Address"
 	ContactAdded           = "ContactAdded"
+	ContactUpdating        = "ContactUpdating"
+	ContactUpdated         = "ContactUpdated"
+	ContactDeleting        = "ContactDeleting"
+	ContactDeleted         = "ContactDeleted"
+	Name                   = "Name"
+	Phones                 = "Phones"
+	Emails                 = "Emails"
+	Type                   = "Type"
+	AddPhone               = "AddPhone"
+	AddEmail               = "AddEmail"
+	AddAddress             = "AddAddress"
 	PhoneNumber            = "PhoneNumber"
 	SearchByFullName       = "SearchByFullName"
 	LineSeparator          = "---------------"
 	RequestValidationError = "RequestValidationError"
+	FixInvalidFields       = "FixInvalidFields"
 
-	PhoneNumberRegex = "^[+]?(?:[91]{2})?[0-9]{10}$"
-	EmailRegex       = "^[a-zA0-Z9._%+\\-]+@[a-zA0-Z9.\\-]+\\.[a-z]{2,4}$"
-	SalutationRegex  = "^(((S(h)?r(i|e+)|([MDS][rs])|(Master|Mister|Miss|Pandit|Pt|Prof|Rev|Rt|Hon|St|CA|Ma(d|')?(a)?m))[\\.]?[\\s])+)"
-	PinCodeRegex     = "^([0-9]{6})$"
+	ValidationRequired          = "ValidationRequired"
+	ValidationFirstNameFormat   = "ValidationFirstNameFormat"
+	ValidationLastNameFormat    = "ValidationLastNameFormat"
+	ValidationEmailFormat       = "ValidationEmailFormat"
+	ValidationPhoneNumberFormat = "ValidationPhoneNumberFormat"
+	ValidationOneOf             = "ValidationOneOf"
+	ValidationPostalCodeFormat  = "ValidationPostalCodeFormat"
+	ValidationInvalid           = "ValidationInvalid"
 
-	Country     = "Country"
-	Zip         = "Zip"
-	State       = "State"
-	City        = "City"
-	Street      = "Street"
-	AddressType = "Personal"
+	EmailRegex      = "^[a-zA0-Z9._%+\\-]+@[a-zA0-Z9.\\-]+\\.[a-z]{2,4}$"
+	SalutationRegex = "^(((S(h)?r(i|e+)|([MDS][rs])|(Master|Mister|Miss|Pandit|Pt|Prof|Rev|Rt|Hon|St|CA|Ma(d|')?(a)?m))[\\.]?[\\s])+)"
+
+	Country = "Country"
+	Zip     = "Zip"
+	State   = "State"
+	City    = "City"
+	Street  = "Street"
 )
@@ -0,0 +1,5 @@
+// Package contactspb holds the messages and the gRPC client and server of proto/addressbook/v1/contacts.proto.
+// Regenerate it with buf, protoc-gen-go and protoc-gen-go-grpc on the PATH.
+package contactspb
+
+//go:generate buf generate --template ../buf.gen.yaml --output .. ../proto
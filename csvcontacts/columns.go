@@ -0,0 +1,164 @@
+// Package csvcontacts reads contacts from the CSV exports of Google Contacts, Outlook and this book, and writes the book as CSV
+package csvcontacts
+
+import (
+	"GoAddressBook/models"
+	"regexp"
+	"strings"
+)
+
+const (
+	phoneKind   = "phone"
+	emailKind   = "email"
+	addressKind = "address"
+
+	firstNamePart = "first_name"
+	lastNamePart  = "last_name"
+	fullNamePart  = "name"
+	createdOnPart = "created_on"
+	typePart      = "type"
+	valuePart     = "value"
+	streetPart    = "street"
+	cityPart      = "city"
+	statePart     = "state"
+	zipPart       = "zip"
+	countryPart   = "country"
+	formattedPart = "formatted"
+
+	// multiValueSeparator separates several values in one Google Contacts cell
+	multiValueSeparator = " ::: "
+)
+
+// column tells what a header of the file fills in a contact
+type column struct {
+	kind      string // phone, email or address for the columns of a list, empty for the name columns
+	group     string // columns of the same group fill the same phone, email or address
+	part      string // what the column holds
+	fixedType string // type implied by the header, such as mobile for Outlook's "Mobile Phone"
+}
+
+// nameColumns maps the name headers of every format
+var nameColumns = map[string]string{
+	"first_name":  firstNamePart,
+	"first name":  firstNamePart,
+	"given name":  firstNamePart,
+	"last_name":   lastNamePart,
+	"last name":   lastNamePart,
+	"family name": lastNamePart,
+	"name":        fullNamePart,
+	"created_on":  createdOnPart,
+}
+
+// numberedColumns matches the numbered headers of Google Contacts, such as "Phone 1 - Value", and of our own format, such as "phone_1_number"
+var numberedColumns = []struct {
+	pattern *regexp.Regexp
+	kind    string
+	parts   map[string]string
+}{
+	{regexp.MustCompile(`^phone (\d+) - (.+)$`), phoneKind, map[string]string{"label": typePart, "type": typePart, "value": valuePart}},
+	{regexp.MustCompile(`^e-?mail (\d+) - (.+)$`), emailKind, map[string]string{"label": typePart, "type": typePart, "value": valuePart}},
+	{regexp.MustCompile(`^address (\d+) - (.+)$`), addressKind, map[string]string{
+		"label": typePart, "type": typePart, "formatted": formattedPart, "street": streetPart, "city": cityPart,
+		"region": statePart, "postal code": zipPart, "country": countryPart,
+	}},
+	{regexp.MustCompile(`^phone_(\d+)_(.+)$`), phoneKind, map[string]string{"type": typePart, "number": valuePart}},
+	{regexp.MustCompile(`^email_(\d+)_(.+)$`), emailKind, map[string]string{"type": typePart, "address": valuePart}},
+	{regexp.MustCompile(`^address_(\d+)_(.+)$`), addressKind, map[string]string{
+		"type": typePart, "street": streetPart, "city": cityPart, "state": statePart, "zip": zipPart, "country": countryPart,
+	}},
+}
+
+// outlookColumns maps the fixed headers of Outlook, each phone or address kind having its own columns
+var outlookColumns = map[string]column{
+	"e-mail address":   {kind: emailKind, group: "e-mail 1", part: valuePart},
+	"e-mail 2 address": {kind: emailKind, group: "e-mail 2", part: valuePart},
+	"e-mail 3 address": {kind: emailKind, group: "e-mail 3", part: valuePart},
+	"primary phone":    {kind: phoneKind, group: "primary", part: valuePart, fixedType: models.MobilePhone},
+	"mobile phone":     {kind: phoneKind, group: "mobile", part: valuePart, fixedType: models.MobilePhone},
+	"business phone":   {kind: phoneKind, group: "business", part: valuePart, fixedType: models.WorkPhone},
+	"business phone 2": {kind: phoneKind, group: "business 2", part: valuePart, fixedType: models.WorkPhone},
+	"home phone":       {kind: phoneKind, group: "home", part: valuePart, fixedType: models.HomePhone},
+	"home phone 2":     {kind: phoneKind, group: "home 2", part: valuePart, fixedType: models.HomePhone},
+	"other phone":      {kind: phoneKind, group: "other", part: valuePart, fixedType: models.MobilePhone},
+}
+
+// outlookAddresses lists the prefixes of Outlook's address columns, such as "Business Street"
+var outlookAddresses = map[string]string{
+	"home":     models.PersonalAddress,
+	"business": models.ProfessionalAddress,
+	"other":    models.PersonalAddress,
+}
+
+var outlookAddressParts = map[string]string{
+	"street":         streetPart,
+	"city":           cityPart,
+	"state":          statePart,
+	"postal code":    zipPart,
+	"country/region": countryPart,
+	"country":        countryPart,
+}
+
+// mapColumns resolves every header of the file, leaving the unknown ones empty so they are ignored
+func mapColumns(header []string) []column {
+	columns := make([]column, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[i] = mapColumn(name)
+	}
+	return columns
+}
+
+func mapColumn(name string) column {
+	if part, found := nameColumns[name]; found {
+		return column{part: part}
+	}
+	if outlook, found := outlookColumns[name]; found {
+		return outlook
+	}
+	for _, numbered := range numberedColumns {
+		if match := numbered.pattern.FindStringSubmatch(name); match != nil {
+			if part, found := numbered.parts[match[2]]; found {
+				return column{kind: numbered.kind, group: numbered.kind + " " + match[1], part: part}
+			}
+			return column{}
+		}
+	}
+	for prefix, addressType := range outlookAddresses {
+		if rest, found := strings.CutPrefix(name, prefix+" "); found {
+			if part, found := outlookAddressParts[rest]; found {
+				return column{kind: addressKind, group: "address " + prefix, part: part, fixedType: addressType}
+			}
+		}
+	}
+	return column{}
+}
+
+// labelType converts a free label such as "Mobile", "Work" or "Business" to a type of the book
+func labelType(kind, label string) string {
+	label = strings.ToLower(label)
+	switch kind {
+	case phoneKind:
+		switch {
+		case strings.Contains(label, "work") || strings.Contains(label, "business"):
+			return models.WorkPhone
+		case strings.Contains(label, "home"):
+			return models.HomePhone
+		default:
+			return models.MobilePhone
+		}
+	case emailKind:
+		if strings.Contains(label, "work") || strings.Contains(label, "business") {
+			return models.WorkEmail
+		}
+		return models.PersonalEmail
+	default:
+		switch {
+		case strings.Contains(label, "work") || strings.Contains(label, "business") || strings.Contains(label, "professional"):
+			return models.ProfessionalAddress
+		case strings.Contains(label, "billing"):
+			return models.BillingAddress
+		default:
+			return models.PersonalAddress
+		}
+	}
+}
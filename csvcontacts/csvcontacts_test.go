@@ -0,0 +1,112 @@
+package csvcontacts
+
+import (
+	"GoAddressBook/models"
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// importedOn stands for the creation date of the contacts of exports without one
+var importedOn = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestDecodeExports reads every export of testdata and checks it against its golden file, the same contacts written in our format
+func TestDecodeExports(t *testing.T) {
+	exports, err := filepath.Glob(filepath.Join("testdata", "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, export := range exports {
+		t.Run(filepath.Base(export), func(t *testing.T) {
+			file, err := os.Open(export)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			start := time.Now()
+			contacts, err := Decode(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range contacts {
+				if !contacts[i].CreatedOn.Before(start) {
+					contacts[i].CreatedOn = importedOn
+				}
+			}
+
+			var got bytes.Buffer
+			if err = Encode(&got, contacts); err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(export, ".csv") + ".golden"
+			if *update {
+				if err = os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("decoded as\n%s\nwant\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestDecodeGooglePrimary(t *testing.T) {
+	file := "Name,Phone 1 - Type,Phone 1 - Value,Phone 2 - Type,Phone 2 - Value\n" +
+		"Ada Lovelace,Home,020 7946 0000,* Mobile,07700 900000\n"
+	contacts, err := Decode(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Phone{
+		{Type: models.HomePhone, Number: "02079460000"},
+		{Type: models.MobilePhone, Number: "07700900000", Primary: true},
+	}
+	if len(contacts) != 1 || !reflect.DeepEqual(contacts[0].Phones, want) {
+		t.Errorf("phones = %+v, want %+v", contacts, want)
+	}
+}
+
+func TestDecodeWithoutNameColumn(t *testing.T) {
+	if _, err := Decode(strings.NewReader("phone,email\n+14155550100,ada@example.com\n")); !errors.Is(err, MissingNameColumns) {
+		t.Errorf("error = %v, want %v", err, MissingNameColumns)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	contacts := []models.Contact{
+		{FirstName: "Ada", LastName: "Lovelace",
+			Phones: []models.Phone{
+				{Type: models.MobilePhone, Number: "+447700900000", Primary: true},
+				{Type: models.HomePhone, Number: "+442079460000"},
+			},
+			Emails:    []models.Email{{Type: models.WorkEmail, Address: "ada@example.com", Primary: true}},
+			Addresses: []models.Address{{Type: models.ProfessionalAddress, Street: "12 St James Square, London", City: "London", Country: "UK", Primary: true}},
+			CreatedOn: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{FirstName: "Alan", LastName: "Turing", CreatedOn: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)},
+	}
+	var file bytes.Buffer
+	if err := Encode(&file, contacts); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, contacts) {
+		t.Errorf("got  %+v\nwant %+v", decoded, contacts)
+	}
+}
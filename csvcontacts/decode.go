@@ -0,0 +1,150 @@
+package csvcontacts
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var MissingNameColumns = errors.New("no name column found in the CSV header")
+
+// group gathers the cells of one phone, email or address of a row
+type group struct {
+	kind      string
+	fixedType string
+	parts     map[string]string
+}
+
+// Decode reads the contacts of a CSV file with a header, one contact per row, whatever of the supported formats it's in.
+// The contacts are not validated, so each row can be checked on its own when imported.
+func Decode(r io.Reader) ([]models.Contact, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := mapColumns(header)
+	if !hasNameColumn(columns) {
+		return nil, MissingNameColumns
+	}
+
+	var contacts []models.Contact
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return contacts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", len(contacts)+1, err)
+		}
+		contacts = append(contacts, toContact(columns, record))
+	}
+}
+
+func hasNameColumn(columns []column) bool {
+	for _, column := range columns {
+		if column.kind == "" && (column.part == firstNamePart || column.part == lastNamePart || column.part == fullNamePart) {
+			return true
+		}
+	}
+	return false
+}
+
+func toContact(columns []column, record []string) models.Contact {
+	var contact models.Contact
+	var fullName string
+	groups := map[string]*group{}
+	var order []string
+
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		column := columns[i]
+		value = strings.TrimSpace(value)
+		switch {
+		case column.part == "" || value == "":
+			continue
+		case column.kind != "":
+			g, found := groups[column.group]
+			if !found {
+				g = &group{kind: column.kind, fixedType: column.fixedType, parts: map[string]string{}}
+				groups[column.group] = g
+				order = append(order, column.group)
+			}
+			g.parts[column.part] = value
+		case column.part == firstNamePart:
+			contact.FirstName = value
+		case column.part == lastNamePart:
+			contact.LastName = value
+		case column.part == fullNamePart:
+			fullName = value
+		case column.part == createdOnPart:
+			contact.CreatedOn, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+
+	if contact.FirstName == "" && contact.LastName == "" {
+		contact.FirstName, _, contact.LastName = utility.GetFirstMiddleAndLastNamesFromFullName(fullName)
+	}
+	for _, name := range order {
+		addGroup(&contact, groups[name])
+	}
+	if contact.CreatedOn.IsZero() {
+		contact.CreatedOn = time.Now()
+	}
+	contact.NormalizePrimary()
+	return contact
+}
+
+// addGroup adds the phones, emails or address of a group, Google marking the primary one with a leading "* " on its label
+func addGroup(contact *models.Contact, g *group) {
+	label := g.parts[typePart]
+	primary := strings.HasPrefix(label, "* ")
+	label = strings.TrimPrefix(label, "* ")
+	entryType := g.fixedType
+	if entryType == "" {
+		entryType = labelType(g.kind, label)
+	}
+
+	switch g.kind {
+	case phoneKind:
+		for _, number := range strings.Split(g.parts[valuePart], multiValueSeparator) {
+			if number = utility.CleanPhoneNumber(number); number != "" {
+				contact.Phones = append(contact.Phones, models.Phone{Type: entryType, Number: number, Primary: primary})
+				primary = false
+			}
+		}
+	case emailKind:
+		for _, address := range strings.Split(g.parts[valuePart], multiValueSeparator) {
+			if address = strings.TrimSpace(address); address != "" {
+				contact.Emails = append(contact.Emails, models.Email{Type: entryType, Address: address, Primary: primary})
+				primary = false
+			}
+		}
+	case addressKind:
+		address := models.Address{
+			Type:    entryType,
+			Street:  g.parts[streetPart],
+			City:    g.parts[cityPart],
+			State:   g.parts[statePart],
+			Zip:     g.parts[zipPart],
+			Country: g.parts[countryPart],
+			Primary: primary,
+		}
+		if address == (models.Address{Type: entryType, Primary: primary}) {
+			if g.parts[formattedPart] == "" {
+				return
+			}
+			address.Street = strings.Join(strings.Fields(strings.ReplaceAll(g.parts[formattedPart], "\n", ", ")), " ")
+		}
+		contact.Addresses = append(contact.Addresses, address)
+	}
+}
@@ -0,0 +1,86 @@
+package csvcontacts
+
+import (
+	"GoAddressBook/models"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Encode writes the contacts in our own CSV format, with as many numbered phone, email and address columns as the
+// contact having the most of them needs. The primary entries come first, so they stay primary when imported back.
+func Encode(w io.Writer, contacts []models.Contact) error {
+	var phones, emails, addresses int
+	for _, contact := range contacts {
+		phones = maximum(phones, len(contact.Phones))
+		emails = maximum(emails, len(contact.Emails))
+		addresses = maximum(addresses, len(contact.Addresses))
+	}
+
+	header := []string{"first_name", "last_name"}
+	for i := 1; i <= phones; i++ {
+		header = append(header, fmt.Sprintf("phone_%d_type", i), fmt.Sprintf("phone_%d_number", i))
+	}
+	for i := 1; i <= emails; i++ {
+		header = append(header, fmt.Sprintf("email_%d_type", i), fmt.Sprintf("email_%d_address", i))
+	}
+	for i := 1; i <= addresses; i++ {
+		for _, part := range []string{"type", "street", "city", "state", "zip", "country"} {
+			header = append(header, fmt.Sprintf("address_%d_%s", i, part))
+		}
+	}
+	header = append(header, "created_on")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, contact := range contacts {
+		record := make([]string, 0, len(header))
+		record = append(record, contact.FirstName, contact.LastName)
+
+		contactPhones := models.PrimaryFirst(contact.Phones, func(phone models.Phone) bool { return phone.Primary })
+		for i := 0; i < phones; i++ {
+			if i < len(contactPhones) {
+				record = append(record, contactPhones[i].Type, contactPhones[i].Number)
+			} else {
+				record = append(record, "", "")
+			}
+		}
+		contactEmails := models.PrimaryFirst(contact.Emails, func(email models.Email) bool { return email.Primary })
+		for i := 0; i < emails; i++ {
+			if i < len(contactEmails) {
+				record = append(record, contactEmails[i].Type, contactEmails[i].Address)
+			} else {
+				record = append(record, "", "")
+			}
+		}
+		contactAddresses := models.PrimaryFirst(contact.Addresses, func(address models.Address) bool { return address.Primary })
+		for i := 0; i < addresses; i++ {
+			if i < len(contactAddresses) {
+				address := contactAddresses[i]
+				record = append(record, address.Type, address.Street, address.City, address.State, address.Zip, address.Country)
+			} else {
+				record = append(record, "", "", "", "", "", "")
+			}
+		}
+
+		var createdOn string
+		if !contact.CreatedOn.IsZero() {
+			createdOn = contact.CreatedOn.Format(time.RFC3339)
+		}
+		if err := writer.Write(append(record, createdOn)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func maximum(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,137 @@
+package fulltext
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for text, want := range map[string][]string{
+		"Ada Lovelace-King":                 {"ada", "lovelace", "king"},
+		"+91 88045-60520":                   {"91", "88045", "60520"},
+		"Straße":                            {"strasse"},
+		"Damden Neptunia, Bengaluru 560037": {"damden", "neptunia", "bengaluru", "560037"},
+		"  ,;  ":                            {},
+	} {
+		if got := Tokenize(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for query, want := range map[string]Node{
+		"ada":                       Term{Value: "ada"},
+		"City:Pune":                 Term{Field: "city", Value: "Pune"},
+		`street:"damden neptunia"`:  Term{Field: "street", Value: "damden neptunia"},
+		"ada lovelace":              And{Operands: []Node{Term{Value: "ada"}, Term{Value: "lovelace"}}},
+		"ada AND london OR turing":  Or{Operands: []Node{And{Operands: []Node{Term{Value: "ada"}, Term{Value: "london"}}}, Term{Value: "turing"}}},
+		"(ada OR alan) city:london": And{Operands: []Node{Or{Operands: []Node{Term{Value: "ada"}, Term{Value: "alan"}}}, Term{Field: "city", Value: "london"}}},
+	} {
+		got, err := Parse(query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", query, got, want)
+		}
+	}
+
+	for _, query := range []string{"", "   ", `street:"damden`, "city:", "(ada OR alan", "ada )", "OR ada"} {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("Parse(%q) succeeded", query)
+		}
+	}
+}
+
+// contacts indexes a few contacts under their name, city and phone fields, "name" standing for both name fields
+func contacts() *Index {
+	index := NewIndex(map[string][]string{"name": {"first_name", "last_name"}})
+	index.Add("ada", Document{"first_name": {"Ada"}, "last_name": {"Lovelace"}, "city": {"London"}, "phone": {"+44 20 7946 0000"}})
+	index.Add("alan", Document{"first_name": {"Alan"}, "last_name": {"Turing"}, "city": {"Wilmslow"}, "street": {"Adlington Road"}})
+	index.Add("grace", Document{"first_name": {"Grace"}, "last_name": {"Hopper"}, "city": {"New York"}, "street": {"Lovelace Avenue"}})
+	return index
+}
+
+func search(t *testing.T, index *Index, query string) string {
+	t.Helper()
+	matches, err := index.Search(query)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	ids := make([]string, 0, len(matches))
+	for id := range matches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, " ")
+}
+
+func TestSearch(t *testing.T) {
+	index := contacts()
+	for query, want := range map[string]string{
+		"lovelace":                 "ada grace",
+		"name:lovelace":            "ada",
+		"street:lovelace":          "grace",
+		"LONDON":                   "ada",
+		`city:"new york"`:          "grace",
+		`"york new"`:               "grace",
+		`city:"new london"`:        "",
+		"ad*":                      "ada alan",
+		"first_name:a*":            "ada alan",
+		"7946":                     "ada",
+		"ada turing":               "",
+		"ada OR turing":            "ada alan",
+		"lovelace AND city:london": "ada",
+		"(ada OR alan) wilmslow":   "alan",
+		"grace OR ada lovelace":    "ada grace",
+		"nobody":                   "",
+	} {
+		if got := search(t, index, query); got != want {
+			t.Errorf("Search(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestRemoveDropsPostings(t *testing.T) {
+	index := contacts()
+	old := Document{"first_name": {"Grace"}, "last_name": {"Hopper"}, "city": {"New York"}, "street": {"Lovelace Avenue"}}
+
+	// An update removes the old document before adding the new one
+	index.Remove("grace", old)
+	index.Add("grace", Document{"first_name": {"Grace"}, "last_name": {"Hopper"}, "city": {"Arlington"}})
+	if got := search(t, index, "york"); got != "" {
+		t.Errorf("old city still matches %q", got)
+	}
+	if got := search(t, index, "lovelace"); got != "ada" {
+		t.Errorf("old street still matches: %q", got)
+	}
+	if got := search(t, index, "arlington"); got != "grace" {
+		t.Errorf("new city matches %q", got)
+	}
+	if _, found := index.postings["avenue"]; found {
+		t.Error("tokens of no document are left in the postings")
+	}
+
+	// A token repeated in a field stays until its last occurrence is removed
+	index.Add("twice", Document{"street": {"Station Lane", "Mill Lane"}})
+	index.Remove("twice", Document{"street": {"Station Lane"}})
+	if got := search(t, index, "street:lane"); got != "twice" {
+		t.Errorf("street:lane = %q after removing one of two lanes", got)
+	}
+
+	for id, document := range map[string]Document{
+		"ada":   {"first_name": {"Ada"}, "last_name": {"Lovelace"}, "city": {"London"}, "phone": {"+44 20 7946 0000"}},
+		"alan":  {"first_name": {"Alan"}, "last_name": {"Turing"}, "city": {"Wilmslow"}, "street": {"Adlington Road"}},
+		"grace": {"first_name": {"Grace"}, "last_name": {"Hopper"}, "city": {"Arlington"}},
+		"twice": {"street": {"Mill Lane"}},
+	} {
+		index.Remove(id, document)
+	}
+	if len(index.postings) != 0 {
+		t.Errorf("postings left after removing every document: %v", index.postings)
+	}
+}
@@ -0,0 +1,138 @@
+package fulltext
+
+import (
+	"golang.org/x/text/cases"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Document maps the fields of an indexed item to their values
+type Document map[string][]string
+
+// Index is an inverted index of case-folded tokens to the documents and fields containing them
+type Index struct {
+	postings map[string]map[string]map[string]int // Token to document ID to field occurrences
+	aliases  map[string][]string                  // Query field names standing for several indexed fields
+	mutex    sync.RWMutex
+}
+
+// NewIndex returns an empty index, aliases letting a query field stand for several indexed fields
+func NewIndex(aliases map[string][]string) *Index {
+	return &Index{
+		postings: make(map[string]map[string]map[string]int),
+		aliases:  aliases,
+	}
+}
+
+// Add indexes every token of the document fields under id
+func (i *Index) Add(id string, document Document) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for field, values := range document {
+		for _, value := range values {
+			for _, token := range Tokenize(value) {
+				documents, found := i.postings[token]
+				if !found {
+					documents = make(map[string]map[string]int)
+					i.postings[token] = documents
+				}
+				if documents[id] == nil {
+					documents[id] = make(map[string]int)
+				}
+				documents[id][field]++
+			}
+		}
+	}
+}
+
+// Remove drops the tokens of the document previously added under id
+func (i *Index) Remove(id string, document Document) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for field, values := range document {
+		for _, value := range values {
+			for _, token := range Tokenize(value) {
+				fields := i.postings[token][id]
+				if fields == nil {
+					continue
+				}
+				if fields[field] > 1 {
+					fields[field]--
+					continue
+				}
+				delete(fields, field)
+				if len(fields) == 0 {
+					delete(i.postings[token], id)
+				}
+				if len(i.postings[token]) == 0 {
+					delete(i.postings, token)
+				}
+			}
+		}
+	}
+}
+
+// Search returns the IDs of the documents matching the query
+func (i *Index) Search(query string) (map[string]bool, error) {
+	node, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return node.evaluate(i), nil
+}
+
+// lookup returns the documents containing the token, in one of the fields when any is given.
+// A token ending with * matches every token starting with it.
+func (i *Index) lookup(token string, fields []string) map[string]bool {
+	ids := make(map[string]bool)
+	collect := func(documents map[string]map[string]int) {
+		for id, occurrences := range documents {
+			if len(fields) == 0 {
+				ids[id] = true
+				continue
+			}
+			for _, field := range fields {
+				if occurrences[field] > 0 {
+					ids[id] = true
+					break
+				}
+			}
+		}
+	}
+
+	if prefix, ok := strings.CutSuffix(token, "*"); ok {
+		for indexed, documents := range i.postings {
+			if strings.HasPrefix(indexed, prefix) {
+				collect(documents)
+			}
+		}
+		return ids
+	}
+	collect(i.postings[token])
+	return ids
+}
+
+// fields resolves a query field name through the aliases
+func (i *Index) fields(name string) []string {
+	if name == "" {
+		return nil
+	}
+	if fields, found := i.aliases[name]; found {
+		return fields
+	}
+	return []string{name}
+}
+
+// Tokenize splits the text on every character which is neither a letter nor a digit and case-folds the tokens
+func Tokenize(text string) []string {
+	folded := cases.Fold().String(text)
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
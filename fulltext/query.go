@@ -0,0 +1,247 @@
+package fulltext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Node is a parsed full-text query
+type Node interface {
+	evaluate(index *Index) map[string]bool
+}
+
+// And matches the documents matching every operand
+type And struct {
+	Operands []Node
+}
+
+// Or matches the documents matching any operand
+type Or struct {
+	Operands []Node
+}
+
+// Term matches the documents containing every token of the value, within the field when one is given
+type Term struct {
+	Field string
+	Value string
+}
+
+func (n And) evaluate(index *Index) map[string]bool {
+	var ids map[string]bool
+	for _, operand := range n.Operands {
+		matches := operand.evaluate(index)
+		if ids == nil {
+			ids = matches
+			continue
+		}
+		for id := range ids {
+			if !matches[id] {
+				delete(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func (n Or) evaluate(index *Index) map[string]bool {
+	ids := make(map[string]bool)
+	for _, operand := range n.Operands {
+		for id := range operand.evaluate(index) {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func (n Term) evaluate(index *Index) map[string]bool {
+	fields := index.fields(n.Field)
+	tokens := Tokenize(n.Value)
+	if strings.HasSuffix(n.Value, "*") && len(tokens) > 0 {
+		tokens[len(tokens)-1] += "*"
+	}
+
+	var ids map[string]bool
+	for _, token := range tokens {
+		matches := index.lookup(token, fields)
+		if ids == nil {
+			ids = matches
+			continue
+		}
+		for id := range ids {
+			if !matches[id] {
+				delete(ids, id)
+			}
+		}
+	}
+	if ids == nil {
+		return map[string]bool{}
+	}
+	return ids
+}
+
+// Parse reads a query made of terms, optionally scoped to a field as in city:bangaluru, quoted values
+// as in street:"damden neptunia", parentheses and the AND and OR operators. Terms next to each other
+// are implicitly joined by AND, which binds tighter than OR.
+func Parse(query string) (Node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.position < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in search query", p.tokens[p.position].text)
+	}
+	return node, nil
+}
+
+const (
+	wordToken = iota
+	openToken
+	closeToken
+)
+
+type token struct {
+	kind  int
+	text  string // Raw text, used for the operators and error messages
+	field string
+	value string
+}
+
+// lex splits the query into words, field-scoped terms and parentheses
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")"})
+			i++
+		default:
+			start := i
+			var field string
+			var value strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				switch {
+				case runes[i] == ':' && field == "" && value.Len() > 0:
+					field = strings.ToLower(value.String())
+					value.Reset()
+					i++
+				case runes[i] == '"':
+					end := i + 1
+					for end < len(runes) && runes[end] != '"' {
+						end++
+					}
+					if end == len(runes) {
+						return nil, fmt.Errorf("unterminated quote in search query")
+					}
+					value.WriteString(string(runes[i+1 : end]))
+					i = end + 1
+				default:
+					value.WriteRune(runes[i])
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: wordToken, text: string(runes[start:i]), field: field, value: value.String()})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.position >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.position], true
+}
+
+func (p *parser) isOperator(t token, operator string) bool {
+	return t.kind == wordToken && t.field == "" && t.text == operator
+}
+
+func (p *parser) parseOr() (Node, error) {
+	var operands []Node
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		next, ok := p.peek()
+		if !ok || !p.isOperator(next, "OR") {
+			break
+		}
+		p.position++
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return Or{Operands: operands}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var operands []Node
+	for {
+		next, ok := p.peek()
+		if !ok || next.kind == closeToken || p.isOperator(next, "OR") {
+			break
+		}
+		if p.isOperator(next, "AND") {
+			p.position++
+			continue
+		}
+		operand, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	switch len(operands) {
+	case 0:
+		return nil, fmt.Errorf("missing search term")
+	case 1:
+		return operands[0], nil
+	default:
+		return And{Operands: operands}, nil
+	}
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	next, _ := p.peek()
+	p.position++
+	if next.kind == wordToken {
+		if next.value == "" {
+			return nil, fmt.Errorf("missing value for field %q", next.field)
+		}
+		return Term{Field: next.field, Value: next.value}, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	closing, ok := p.peek()
+	if !ok || closing.kind != closeToken {
+		return nil, fmt.Errorf("missing closing parenthesis in search query")
+	}
+	p.position++
+	return node, nil
+}
@@ -0,0 +1,55 @@
+package grpcserver
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/contactspb"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+)
+
+// bufferSize is the size of the in-memory connection between the in-process client and server
+const bufferSize = 1 << 20
+
+// InProcessClient is a client of a ContactService served in the same process over an in-memory connection,
+// for tests that want the whole gRPC stack without a network port
+type InProcessClient struct {
+	contactspb.ContactServiceClient
+	connection *grpc.ClientConn
+	grpcServer *grpc.Server
+}
+
+// NewInProcessClient serves the address book on an in-memory listener and returns a client connected to it
+func NewInProcessClient(book *addressbook.AddressBook) (*InProcessClient, error) {
+	listener := bufconn.Listen(bufferSize)
+	grpcServer := grpc.NewServer()
+	NewServer(book).Register(grpcServer)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	connection, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, err
+	}
+	return &InProcessClient{
+		ContactServiceClient: contactspb.NewContactServiceClient(connection),
+		connection:           connection,
+		grpcServer:           grpcServer,
+	}, nil
+}
+
+// Close disconnects the client and stops the server
+func (c *InProcessClient) Close() error {
+	err := c.connection.Close()
+	c.grpcServer.Stop()
+	return err
+}
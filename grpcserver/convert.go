@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"GoAddressBook/contactspb"
+	"GoAddressBook/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// contactToProto converts a contact of the book into its message
+func contactToProto(contact models.Contact) *contactspb.Contact {
+	message := &contactspb.Contact{
+		Id:        contact.ID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+	}
+	if !contact.CreatedOn.IsZero() {
+		message.CreatedOn = timestamppb.New(contact.CreatedOn)
+	}
+	for _, phone := range contact.Phones {
+		message.Phones = append(message.Phones, &contactspb.Phone{Type: phone.Type, Number: phone.Number, Primary: phone.Primary})
+	}
+	for _, email := range contact.Emails {
+		message.Emails = append(message.Emails, &contactspb.Email{Type: email.Type, Address: email.Address, Primary: email.Primary})
+	}
+	for _, address := range contact.Addresses {
+		message.Addresses = append(message.Addresses, &contactspb.Address{
+			Type:    address.Type,
+			Street:  address.Street,
+			City:    address.City,
+			State:   address.State,
+			Zip:     address.Zip,
+			Country: address.Country,
+			Primary: address.Primary,
+		})
+	}
+	return message
+}
+
+// contactFromProto converts a message into a contact of the book
+func contactFromProto(message *contactspb.Contact) models.Contact {
+	contact := models.Contact{
+		ID:        message.GetId(),
+		FirstName: message.GetFirstName(),
+		LastName:  message.GetLastName(),
+	}
+	if message.GetCreatedOn() != nil {
+		contact.CreatedOn = message.GetCreatedOn().AsTime()
+	}
+	for _, phone := range message.GetPhones() {
+		contact.Phones = append(contact.Phones, models.Phone{Type: phone.GetType(), Number: phone.GetNumber(), Primary: phone.GetPrimary()})
+	}
+	for _, email := range message.GetEmails() {
+		contact.Emails = append(contact.Emails, models.Email{Type: email.GetType(), Address: email.GetAddress(), Primary: email.GetPrimary()})
+	}
+	for _, address := range message.GetAddresses() {
+		contact.Addresses = append(contact.Addresses, models.Address{
+			Type:    address.GetType(),
+			Street:  address.GetStreet(),
+			City:    address.GetCity(),
+			State:   address.GetState(),
+			Zip:     address.GetZip(),
+			Country: address.GetCountry(),
+			Primary: address.GetPrimary(),
+		})
+	}
+	return contact
+}
+
+// contactsToProto converts contacts of the book into messages
+func contactsToProto(contacts []models.Contact) []*contactspb.Contact {
+	messages := make([]*contactspb.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		messages = append(messages, contactToProto(contact))
+	}
+	return messages
+}
@@ -0,0 +1,40 @@
+package grpcserver
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/utility"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts an error of the address book or of the validation of a contact into a gRPC status,
+// invalid contacts listing every invalid field in a BadRequest detail
+func statusError(err error) error {
+	var validationErr *utility.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range validationErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Code + ": " + field.Message,
+			})
+		}
+		invalid, detailsErr := status.New(codes.InvalidArgument, "the contact is invalid").WithDetails(badRequest)
+		if detailsErr != nil {
+			return status.Error(codes.InvalidArgument, validationErr.Error())
+		}
+		return invalid.Err()
+	case errors.Is(err, addressbook.ContactNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, addressbook.ContactAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, addressbook.UnknownSortField), errors.Is(err, addressbook.InvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	slog.Info("address book call failed", "err", err)
+	return status.Error(codes.Internal, "the address book couldn't be updated")
+}
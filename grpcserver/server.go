@@ -0,0 +1,210 @@
+// Package grpcserver implements the ContactService of contactspb over an address book, for the Go services of the
+// machine that prefer a typed RPC API to the REST one.
+package grpcserver
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/contactspb"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/sagikazarmark/slog-shim"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultBind = "127.0.0.1"
+	DefaultPort = 9090
+
+	// listPageSize is the number of contacts read from the book at once while streaming a listing
+	listPageSize = 100
+)
+
+// Server serves the ContactService over an address book
+type Server struct {
+	contactspb.UnimplementedContactServiceServer
+	Book      *addressbook.AddressBook
+	Validator *validator.Validate
+}
+
+// NewServer returns a Server over the address book
+func NewServer(book *addressbook.AddressBook) *Server {
+	return &Server{
+		Book:      book,
+		Validator: utility.NewValidator(),
+	}
+}
+
+// Register adds the ContactService to the gRPC server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	contactspb.RegisterContactServiceServer(grpcServer, s)
+}
+
+// Address returns the address the server listens on, from the grpc.bind and grpc.port configuration
+func Address() string {
+	bind := viper.GetString(constants.GrpcBind)
+	if bind == "" {
+		bind = DefaultBind
+	}
+	port := viper.GetInt(constants.GrpcPort)
+	if port == 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(bind, strconv.Itoa(port))
+}
+
+// ListenAndServe serves the ContactService on the configured address until the context is done,
+// then stops gracefully once the running calls are over
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", Address())
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	s.Register(grpcServer)
+
+	failed := make(chan error, 1)
+	go func() {
+		slog.Info("address book gRPC service listening", "address", listener.Addr().String())
+		failed <- grpcServer.Serve(listener)
+	}()
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	grpcServer.GracefulStop()
+	return <-failed
+}
+
+// CreateContact validates and adds the contact under a newly generated ID
+func (s *Server) CreateContact(_ context.Context, request *contactspb.CreateContactRequest) (*contactspb.CreateContactResponse, error) {
+	contact := contactFromProto(request.GetContact())
+	// The ID is always generated by the book
+	contact.ID = ""
+	if contact.CreatedOn.IsZero() {
+		contact.CreatedOn = time.Now()
+	}
+	contact.NormalizePrimary()
+	if err := utility.ValidateContact(s.Validator, contact); err != nil {
+		return nil, statusError(err)
+	}
+	id, err := s.Book.AddContact(contact)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	created, _ := s.Book.GetContact(id)
+	return &contactspb.CreateContactResponse{Contact: contactToProto(created)}, nil
+}
+
+// GetContact returns the contact with the ID
+func (s *Server) GetContact(_ context.Context, request *contactspb.GetContactRequest) (*contactspb.GetContactResponse, error) {
+	contact, found := s.Book.GetContact(request.GetId())
+	if !found {
+		return nil, statusError(addressbook.ContactNotFound)
+	}
+	return &contactspb.GetContactResponse{Contact: contactToProto(contact)}, nil
+}
+
+// SearchContacts returns the contacts matching the name, the phone number, the full-text search or the query
+func (s *Server) SearchContacts(_ context.Context, request *contactspb.SearchContactsRequest) (*contactspb.SearchContactsResponse, error) {
+	var contacts []models.Contact
+	switch criteria := request.GetCriteria().(type) {
+	case *contactspb.SearchContactsRequest_Name:
+		limit := int(request.GetLimit())
+		if limit <= 0 {
+			limit = viper.GetInt(constants.SearchLimit)
+		}
+		matches := s.Book.SearchNames(criteria.Name, addressbook.NameSearchOptions{
+			Prefix:      true,
+			MaxDistance: viper.GetInt(constants.SearchMaxDistance),
+			Limit:       limit,
+			Phonetic:    viper.GetBool(constants.SearchPhonetic),
+		})
+		for _, match := range matches {
+			contacts = append(contacts, match.Contact)
+		}
+	case *contactspb.SearchContactsRequest_Phone:
+		if contact, found := s.Book.SearchByPhoneNumber(criteria.Phone); found {
+			contacts = append(contacts, contact)
+		}
+	case *contactspb.SearchContactsRequest_Text:
+		found, err := s.Book.Search(criteria.Text)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		contacts = found
+	case *contactspb.SearchContactsRequest_Query:
+		found, err := s.Book.Query(criteria.Query)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		contacts = found
+	default:
+		return nil, status.Error(codes.InvalidArgument, "a name, a phone, a text or a query to search is needed")
+	}
+	return &contactspb.SearchContactsResponse{Contacts: contactsToProto(contacts)}, nil
+}
+
+// ListContacts streams every contact sorted by the field, reading the book one page at a time
+func (s *Server) ListContacts(request *contactspb.ListContactsRequest, stream contactspb.ContactService_ListContactsServer) error {
+	options := addressbook.ListOptions{
+		SortBy:     request.GetSortBy(),
+		Descending: request.GetDescending(),
+		Limit:      listPageSize,
+	}
+	for {
+		page, err := s.Book.List(options)
+		if err != nil {
+			return statusError(err)
+		}
+		for _, contact := range page.Contacts {
+			if err := stream.Send(&contactspb.ListContactsResponse{Contact: contactToProto(contact)}); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		options.Cursor = page.NextCursor
+	}
+}
+
+// UpdateContact validates the contact and replaces the one with the ID by it
+func (s *Server) UpdateContact(_ context.Context, request *contactspb.UpdateContactRequest) (*contactspb.UpdateContactResponse, error) {
+	id := request.GetId()
+	if _, found := s.Book.GetContact(id); !found {
+		return nil, statusError(addressbook.ContactNotFound)
+	}
+	contact := contactFromProto(request.GetContact())
+	contact.ID = id
+	contact.NormalizePrimary()
+	if err := utility.ValidateContact(s.Validator, contact); err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.Book.UpdateContact(id, contact); err != nil {
+		return nil, statusError(err)
+	}
+	updated, _ := s.Book.GetContact(id)
+	return &contactspb.UpdateContactResponse{Contact: contactToProto(updated)}, nil
+}
+
+// DeleteContact removes the contact with the ID and returns it
+func (s *Server) DeleteContact(_ context.Context, request *contactspb.DeleteContactRequest) (*contactspb.DeleteContactResponse, error) {
+	contact, err := s.Book.DeleteContact(request.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &contactspb.DeleteContactResponse{Contact: contactToProto(contact)}, nil
+}
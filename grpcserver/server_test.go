@@ -0,0 +1,236 @@
+package grpcserver_test
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/contactspb"
+	"GoAddressBook/grpcserver"
+	"GoAddressBook/repository"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newContact(firstName, lastName, number, city string) *contactspb.Contact {
+	return &contactspb.Contact{
+		FirstName: firstName,
+		LastName:  lastName,
+		Phones:    []*contactspb.Phone{{Type: "mobile", Number: number, Primary: true}},
+		Emails:    []*contactspb.Email{{Type: "work", Address: strings.ToLower(firstName) + "@example.com", Primary: true}},
+		Addresses: []*contactspb.Address{{Type: "personal", City: city, Country: "UK", Primary: true}},
+	}
+}
+
+// newClient serves a book holding the contacts, returning a client of it with their IDs in order
+func newClient(t *testing.T, contacts ...*contactspb.Contact) (*grpcserver.InProcessClient, []string) {
+	t.Helper()
+	client, err := grpcserver.NewInProcessClient(addressbook.NewAddressBook(repository.NewMemoryRepository()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	ids := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		created, err := client.CreateContact(context.Background(), &contactspb.CreateContactRequest{Contact: contact})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.GetContact().GetId())
+	}
+	return client, ids
+}
+
+func code(err error) codes.Code {
+	return status.Code(err)
+}
+
+func TestCreateAndGetContact(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newClient(t)
+
+	contact := newContact("Ada", "Lovelace", "+14155550100", "London")
+	contact.Id = "chosen-by-the-client"
+	created, err := client.CreateContact(ctx, &contactspb.CreateContactRequest{Contact: contact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := created.GetContact().GetId()
+	if id == "" || id == contact.Id {
+		t.Errorf("ID = %q, want one generated by the book", id)
+	}
+	if created.GetContact().GetCreatedOn() == nil {
+		t.Error("no creation date")
+	}
+
+	got, err := client.GetContact(ctx, &contactspb.GetContactRequest{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetContact().GetLastName() != "Lovelace" || got.GetContact().GetPhones()[0].GetNumber() != "+14155550100" {
+		t.Errorf("contact = %v", got.GetContact())
+	}
+
+	if _, err = client.GetContact(ctx, &contactspb.GetContactRequest{Id: "missing"}); code(err) != codes.NotFound {
+		t.Errorf("reading a missing contact: %v, want NotFound", err)
+	}
+	duplicate := newContact("Alan", "Turing", "+14155550100", "London")
+	if _, err = client.CreateContact(ctx, &contactspb.CreateContactRequest{Contact: duplicate}); code(err) != codes.AlreadyExists {
+		t.Errorf("creating a contact with a taken number: %v, want AlreadyExists", err)
+	}
+}
+
+func TestCreateInvalidContact(t *testing.T) {
+	client, _ := newClient(t)
+	invalid := newContact("Ada", "Lovelace", "not a number", "London")
+	invalid.Emails = nil
+
+	_, err := client.CreateContact(context.Background(), &contactspb.CreateContactRequest{Contact: invalid})
+	if code(err) != codes.InvalidArgument {
+		t.Fatalf("creating an invalid contact: %v, want InvalidArgument", err)
+	}
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+		}
+	}
+	if len(fields) < 2 {
+		t.Errorf("field violations = %v, want the phone and the emails", fields)
+	}
+}
+
+func TestSearchContacts(t *testing.T) {
+	client, ids := newClient(t,
+		newContact("Ada", "Lovelace", "+14155550100", "London"),
+		newContact("Alan", "Turing", "+14155550101", "Wilmslow"),
+		newContact("Grace", "Hopper", "+14155550102", "Arlington"),
+	)
+
+	tests := []struct {
+		name    string
+		request *contactspb.SearchContactsRequest
+		want    []string
+		code    codes.Code
+	}{
+		{name: "name prefix", want: ids[1:2],
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Name{Name: "Tur"}, Limit: 5}},
+		{name: "phone in another format", want: ids[2:3],
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Phone{Phone: "+1 (415) 555-0102"}}},
+		{name: "unknown phone",
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Phone{Phone: "+14155550199"}}},
+		{name: "text", want: ids[0:1],
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Text{Text: "london"}}},
+		{name: "query", want: []string{ids[2], ids[0]},
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Query{
+				Query: `city != "Wilmslow" ORDER BY last_name`}}},
+		{name: "invalid query", code: codes.InvalidArgument,
+			request: &contactspb.SearchContactsRequest{Criteria: &contactspb.SearchContactsRequest_Query{Query: "city ="}}},
+		{name: "no criteria", code: codes.InvalidArgument, request: &contactspb.SearchContactsRequest{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, err := client.SearchContacts(context.Background(), test.request)
+			if code(err) != test.code {
+				t.Fatalf("error = %v, want %v", err, test.code)
+			}
+			var got []string
+			for _, contact := range found.GetContacts() {
+				got = append(got, contact.GetId())
+			}
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("contacts = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestListContacts(t *testing.T) {
+	client, _ := newClient(t,
+		newContact("Ada", "Lovelace", "+14155550100", "London"),
+		newContact("Alan", "Turing", "+14155550101", "Wilmslow"),
+		newContact("Grace", "Hopper", "+14155550102", "Arlington"),
+	)
+
+	tests := []struct {
+		request *contactspb.ListContactsRequest
+		want    []string
+		code    codes.Code
+	}{
+		{request: &contactspb.ListContactsRequest{}, want: []string{"Ada", "Alan", "Grace"}},
+		{request: &contactspb.ListContactsRequest{SortBy: "city", Descending: true}, want: []string{"Alan", "Ada", "Grace"}},
+		{request: &contactspb.ListContactsRequest{SortBy: "phone"}, code: codes.InvalidArgument},
+	}
+	for _, test := range tests {
+		t.Run(test.request.GetSortBy(), func(t *testing.T) {
+			stream, err := client.ListContacts(context.Background(), test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for {
+				message, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					if code(err) != test.code {
+						t.Fatalf("error = %v, want %v", err, test.code)
+					}
+					return
+				}
+				got = append(got, message.GetContact().GetFirstName())
+			}
+			if test.code != codes.OK {
+				t.Fatalf("listing succeeded, want %v", test.code)
+			}
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("contacts = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAndDeleteContact(t *testing.T) {
+	ctx := context.Background()
+	client, ids := newClient(t,
+		newContact("Ada", "Lovelace", "+14155550100", "London"),
+		newContact("Alan", "Turing", "+14155550101", "Wilmslow"),
+	)
+
+	changed := newContact("Ada", "King", "+14155550103", "London")
+	updated, err := client.UpdateContact(ctx, &contactspb.UpdateContactRequest{Id: ids[0], Contact: changed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetContact().GetId() != ids[0] || updated.GetContact().GetLastName() != "King" {
+		t.Errorf("updated contact = %v", updated.GetContact())
+	}
+	taken := newContact("Ada", "King", "+14155550101", "London")
+	if _, err = client.UpdateContact(ctx, &contactspb.UpdateContactRequest{Id: ids[0], Contact: taken}); code(err) != codes.AlreadyExists {
+		t.Errorf("updating to a taken number: %v, want AlreadyExists", err)
+	}
+	if _, err = client.UpdateContact(ctx, &contactspb.UpdateContactRequest{Id: "missing", Contact: changed}); code(err) != codes.NotFound {
+		t.Errorf("updating a missing contact: %v, want NotFound", err)
+	}
+
+	deleted, err := client.DeleteContact(ctx, &contactspb.DeleteContactRequest{Id: ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.GetContact().GetFirstName() != "Alan" {
+		t.Errorf("deleted contact = %v", deleted.GetContact())
+	}
+	if _, err = client.GetContact(ctx, &contactspb.GetContactRequest{Id: ids[1]}); code(err) != codes.NotFound {
+		t.Errorf("reading a deleted contact: %v, want NotFound", err)
+	}
+	if _, err = client.DeleteContact(ctx, &contactspb.DeleteContactRequest{Id: ids[1]}); code(err) != codes.NotFound {
+		t.Errorf("deleting twice: %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,190 @@
+package ldif
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// entry holds the attributes of an LDIF record by lowercase name
+type entry struct {
+	line       int
+	attributes map[string][]string
+}
+
+func (e entry) first(name string) string {
+	if values := e.attributes[name]; len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// Decode reads every entry of an LDIF file into contacts. Only the primary address is carried by inetOrgPerson,
+// and the contacts are not validated and have no ID, they get one when added to the book.
+func Decode(r io.Reader) ([]models.Contact, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return nil, err
+	}
+	contacts := make([]models.Contact, 0, len(entries))
+	for _, e := range entries {
+		if changeType := e.first("changetype"); changeType != "" && !strings.EqualFold(changeType, "add") {
+			return nil, fmt.Errorf("line %d: unsupported changetype %q", e.line, changeType)
+		}
+		contacts = append(contacts, toContact(e))
+	}
+	return contacts, nil
+}
+
+// readEntries splits the file into records separated by blank lines, unfolding lines and decoding base64 values
+func readEntries(r io.Reader) ([]entry, error) {
+	var entries []entry
+	var lines []string
+	var firstLine int
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+		e := entry{line: firstLine, attributes: map[string][]string{}}
+		for _, line := range lines {
+			name, value, err := parseLine(line)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", firstLine, err)
+			}
+			e.attributes[name] = append(e.attributes[name], value)
+		}
+		lines = nil
+		if _, isVersion := e.attributes["version"]; isVersion && len(e.attributes) == 1 {
+			return nil
+		}
+		delete(e.attributes, "version")
+		entries = append(entries, e)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	number := 0
+	comment := false
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, " "):
+			if comment {
+				continue
+			}
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without a line to continue", number)
+			}
+			lines[len(lines)-1] += line[1:]
+		case strings.HasPrefix(line, "#"):
+			comment = true
+		case strings.TrimSpace(line) == "":
+			comment = false
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			comment = false
+			if len(lines) == 0 {
+				firstLine = number
+			}
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseLine reads "name: value", "name:: base64" and "name;option: value" lines
+func parseLine(line string) (string, string, error) {
+	name, value, found := strings.Cut(line, ":")
+	if !found {
+		return "", "", fmt.Errorf("missing ':' in %q", line)
+	}
+	name, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(name)), ";")
+	switch {
+	case strings.HasPrefix(value, ":"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid base64 value of %s: %w", name, err)
+		}
+		return name, string(decoded), nil
+	case strings.HasPrefix(value, "<"):
+		return "", "", fmt.Errorf("URL value of %s is not supported", name)
+	default:
+		return name, strings.TrimLeft(value, " "), nil
+	}
+}
+
+func toContact(e entry) models.Contact {
+	contact := models.Contact{
+		FirstName: e.first(givenName),
+		LastName:  e.first(sn),
+		CreatedOn: time.Now(),
+	}
+	if contact.FirstName == "" {
+		var lastName string
+		contact.FirstName, _, lastName = utility.GetFirstMiddleAndLastNamesFromFullName(e.first(cn))
+		if contact.LastName == "" {
+			contact.LastName = lastName
+		}
+	}
+
+	for _, phone := range []struct{ attribute, phoneType string }{
+		{mobile, models.MobilePhone},
+		{telephoneNumber, models.WorkPhone},
+		{homePhone, models.HomePhone},
+	} {
+		for _, number := range e.attributes[phone.attribute] {
+			if number = utility.CleanPhoneNumber(number); number != "" {
+				contact.Phones = append(contact.Phones, models.Phone{Type: phone.phoneType, Number: number})
+			}
+		}
+	}
+	for _, address := range e.attributes[mail] {
+		if address = strings.TrimSpace(address); address != "" {
+			contact.Emails = append(contact.Emails, models.Email{Type: models.PersonalEmail, Address: address})
+		}
+	}
+
+	address := models.Address{
+		Type:    models.PersonalAddress,
+		Street:  postalLines(e.first(postalAddress)),
+		City:    e.first(locality),
+		State:   e.first(state),
+		Zip:     e.first(postalCode),
+		Country: e.first(countryName),
+	}
+	if address.Country == "" {
+		address.Country = e.first(country)
+	}
+	if address != (models.Address{Type: models.PersonalAddress}) {
+		contact.Addresses = append(contact.Addresses, address)
+	}
+
+	contact.NormalizePrimary()
+	return contact
+}
+
+// postalLines joins the lines of a postalAddress, separated by $, with commas
+func postalLines(value string) string {
+	var lines []string
+	for _, line := range strings.Split(value, lineSeparator) {
+		if line = strings.TrimSpace(strings.ReplaceAll(line, `\24`, lineSeparator)); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, ", ")
+}
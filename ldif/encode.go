@@ -0,0 +1,126 @@
+package ldif
+
+import (
+	"GoAddressBook/models"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encode writes the contacts as inetOrgPerson entries named by their ID under the base DN, such as
+// uid=<id>,ou=contacts,dc=example,dc=com, or by their name when they have no ID yet. Only the primary address fits in the attributes of an inetOrgPerson.
+func Encode(w io.Writer, contacts []models.Contact, baseDN string) error {
+	if _, err := io.WriteString(w, "version: 1\n"); err != nil {
+		return err
+	}
+	for _, contact := range contacts {
+		fullName := strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+		relativeDN := "uid=" + escapeDN(contact.ID)
+		if contact.ID == "" {
+			relativeDN = "cn=" + escapeDN(fullName)
+		}
+
+		var record strings.Builder
+		record.WriteString("\n")
+		writeAttribute(&record, "dn", relativeDN+","+baseDN)
+		for _, objectClass := range objectClasses {
+			writeAttribute(&record, "objectClass", objectClass)
+		}
+		writeAttribute(&record, "uid", contact.ID)
+		writeAttribute(&record, "cn", fullName)
+		writeAttribute(&record, "sn", contact.LastName)
+		writeAttribute(&record, "givenName", contact.FirstName)
+
+		for _, email := range models.PrimaryFirst(contact.Emails, func(email models.Email) bool { return email.Primary }) {
+			writeAttribute(&record, "mail", email.Address)
+		}
+		for _, phone := range models.PrimaryFirst(contact.Phones, func(phone models.Phone) bool { return phone.Primary }) {
+			switch phone.Type {
+			case models.WorkPhone:
+				writeAttribute(&record, "telephoneNumber", phone.Number)
+			case models.HomePhone:
+				writeAttribute(&record, "homePhone", phone.Number)
+			default:
+				writeAttribute(&record, "mobile", phone.Number)
+			}
+		}
+
+		if len(contact.Addresses) > 0 {
+			address := contact.PrimaryAddress()
+			writeAttribute(&record, "postalAddress", strings.ReplaceAll(address.Street, lineSeparator, `\24`))
+			writeAttribute(&record, "l", address.City)
+			writeAttribute(&record, "st", address.State)
+			writeAttribute(&record, "postalCode", address.Zip)
+			// c only holds two letter country codes, co holds country names
+			if len(address.Country) == 2 {
+				writeAttribute(&record, "c", strings.ToUpper(address.Country))
+			} else {
+				writeAttribute(&record, "co", address.Country)
+			}
+		}
+
+		if _, err := io.WriteString(w, record.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAttribute writes a non-empty value, base64 encoded when it isn't a safe string
+func writeAttribute(record *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	line := name + ": " + value
+	if !isSafe(value) {
+		line = name + ":: " + base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	record.WriteString(fold(line))
+}
+
+// isSafe tells whether the value is a SAFE-STRING of RFC 2849, written as is
+func isSafe(value string) bool {
+	if strings.HasPrefix(value, " ") || strings.HasPrefix(value, ":") || strings.HasPrefix(value, "<") || strings.HasSuffix(value, " ") {
+		return false
+	}
+	for _, r := range value {
+		if r == 0 || r == '\n' || r == '\r' || r > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// fold splits the line every 76 characters, continuation lines starting with a space
+func fold(line string) string {
+	var folded strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\n ")
+		line = line[cut:]
+		limit = maxLineLength - 1
+	}
+	folded.WriteString(line)
+	folded.WriteString("\n")
+	return folded.String()
+}
+
+// escapeDN escapes the characters with a special meaning in a distinguished name
+func escapeDN(value string) string {
+	var escaped strings.Builder
+	for i, r := range value {
+		if strings.ContainsRune(`,+"\<>;=`, r) || (i == 0 && (r == ' ' || r == '#')) {
+			escaped.WriteString(fmt.Sprintf(`\%c`, r))
+			continue
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
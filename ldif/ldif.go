@@ -0,0 +1,28 @@
+// Package ldif reads and writes contacts as inetOrgPerson entries in the LDAP Data Interchange Format (RFC 2849)
+package ldif
+
+const (
+	// maxLineLength is the number of characters after which lines are folded
+	maxLineLength = 76
+
+	cn              = "cn"
+	sn              = "sn"
+	givenName       = "givenname"
+	uid             = "uid"
+	mail            = "mail"
+	telephoneNumber = "telephonenumber"
+	mobile          = "mobile"
+	homePhone       = "homephone"
+	postalAddress   = "postaladdress"
+	locality        = "l"
+	state           = "st"
+	postalCode      = "postalcode"
+	country         = "c"
+	countryName     = "co"
+
+	// lineSeparator separates the lines of a postalAddress
+	lineSeparator = "$"
+)
+
+// objectClasses are written on every exported entry
+var objectClasses = []string{"top", "person", "organizationalPerson", "inetOrgPerson"}
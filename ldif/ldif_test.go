@@ -0,0 +1,129 @@
+package ldif
+
+import (
+	"GoAddressBook/models"
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeFile(t *testing.T) {
+	file, err := os.Open("testdata/contacts.ldif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	contacts, err := Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range contacts {
+		if contacts[i].CreatedOn.IsZero() {
+			t.Errorf("contact %d has no creation date", i)
+		}
+		contacts[i].CreatedOn = time.Time{}
+	}
+
+	want := []models.Contact{
+		{FirstName: "Ada", LastName: "Lovelace",
+			Phones: []models.Phone{
+				{Type: models.MobilePhone, Number: "+447700900000", Primary: true},
+				{Type: models.WorkPhone, Number: "+442079460000"},
+			},
+			Emails: []models.Email{
+				{Type: models.PersonalEmail, Address: "ada@example.com", Primary: true},
+				{Type: models.PersonalEmail, Address: "countess@example.org"},
+			},
+			Addresses: []models.Address{{Type: models.PersonalAddress, Street: "12 St James Square, Westminster", City: "London", Zip: "SW1Y 4JH", Country: "GB", Primary: true}},
+		},
+		// Named by its base64 cn only, with folded values
+		{FirstName: "Zoë", LastName: "Brontë",
+			Phones:    []models.Phone{{Type: models.HomePhone, Number: "01614960000", Primary: true}},
+			Addresses: []models.Address{{Type: models.PersonalAddress, Country: "United Kingdom", Primary: true}},
+		},
+	}
+	if !reflect.DeepEqual(contacts, want) {
+		t.Errorf("got  %+v\nwant %+v", contacts, want)
+	}
+}
+
+func TestDecodeRejectsChanges(t *testing.T) {
+	for name, record := range map[string]string{
+		"modify":             "dn: cn=Ada\nchangetype: modify\nreplace: mail\nmail: ada@example.com\n",
+		"url value":          "dn: cn=Ada\njpegPhoto:< file:///tmp/ada.jpg\n",
+		"invalid base64":     "dn: cn=Ada\ncn:: not base64!\n",
+		"continuation first": " cn: Ada\n",
+		"missing colon":      "dn: cn=Ada\ncn Ada\n",
+	} {
+		if _, err := Decode(strings.NewReader(record)); err == nil {
+			t.Errorf("%s: decoded without error", name)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	contacts := []models.Contact{
+		{ID: "0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11", FirstName: "Ada", LastName: "Lovelace",
+			Phones: []models.Phone{
+				{Type: models.HomePhone, Number: "+442079460000"},
+				{Type: models.MobilePhone, Number: "+447700900000", Primary: true},
+			},
+			Emails: []models.Email{
+				{Type: models.PersonalEmail, Address: "ada@home.example.com"},
+				{Type: models.WorkEmail, Address: "ada@example.com", Primary: true},
+			},
+			Addresses: []models.Address{
+				{Street: "Ockham Park", City: "Ockham", Country: "United Kingdom"},
+				{Street: "12 St James Square", City: "London", Country: "gb", Primary: true},
+			},
+		},
+		{FirstName: "Zoë", LastName: "Brontë, Jr"},
+	}
+	var file bytes.Buffer
+	if err := Encode(&file, contacts, "ou=contacts,dc=example,dc=com"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `version: 1
+
+dn: uid=0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11,ou=contacts,dc=example,dc=com
+objectClass: top
+objectClass: person
+objectClass: organizationalPerson
+objectClass: inetOrgPerson
+uid: 0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11
+cn: Ada Lovelace
+sn: Lovelace
+givenName: Ada
+mail: ada@example.com
+mail: ada@home.example.com
+mobile: +447700900000
+homePhone: +442079460000
+postalAddress: 12 St James Square
+l: London
+c: GB
+
+dn:: Y249Wm/DqyBCcm9udMOrXCwgSnIsb3U9Y29udGFjdHMsZGM9ZXhhbXBsZSxkYz1jb20=
+objectClass: top
+objectClass: person
+objectClass: organizationalPerson
+objectClass: inetOrgPerson
+cn:: Wm/DqyBCcm9udMOrLCBKcg==
+sn:: QnJvbnTDqywgSnI=
+givenName:: Wm/Dqw==
+`
+	if file.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", file.String(), want)
+	}
+
+	decoded, err := Decode(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[1].FirstName != "Zoë" || decoded[1].LastName != "Brontë, Jr" {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
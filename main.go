@@ -3,23 +3,54 @@ package main
 import (
 	"GoAddressBook/addressbook"
 	"GoAddressBook/cli"
+	"GoAddressBook/command"
 	"GoAddressBook/configs"
+	"GoAddressBook/constants"
+	"GoAddressBook/repository"
 	"github.com/sagikazarmark/slog-shim"
+	"github.com/spf13/viper"
+	"io"
+	"os"
 )
 
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the subcommand given in the arguments, or the interactive menu without any, and returns the exit code
+func run(args []string) int {
 	configs.NewConfig()
-	bookInstance := addressbook.NewAddressBook()
-	err := bookInstance.LoadFromFile()
+	repo, err := repository.NewRepository()
+	if err != nil {
+		slog.Info("failed to instance address book repository : ", err)
+		return command.ExitFailure
+	}
+	if closer, ok := repo.(io.Closer); ok {
+		defer closer.Close()
+	}
+	bookInstance := addressbook.NewAddressBook(repo)
+	bookInstance.SetPhoneRegion(viper.GetString(constants.PhoneDefaultRegion))
+	if viper.GetBool(constants.SearchPhonetic) {
+		bookInstance.EnablePhoneticIndex()
+	}
+	err = bookInstance.LoadFromFile()
 	if err != nil {
 		slog.Info("failed to load data from json file : ", err)
-		return
+		return command.ExitFailure
+	}
+	if len(args) > 0 {
+		commandInstance, err := command.NewCommand(bookInstance)
+		if err != nil {
+			slog.Info("Error while instancing command :", err)
+			return command.ExitFailure
+		}
+		return commandInstance.Run(args)
 	}
 	cliInstance, err := cli.NewCliInstance(bookInstance)
 	if err != nil {
 		slog.Info("Error while instancing command-line interface :", err)
-		return
+		return command.ExitFailure
 	}
 	cliInstance.Menu()
-	return
+	return command.ExitSuccess
 }
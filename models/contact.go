@@ -1,26 +1,187 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	MobilePhone = "mobile"
+	WorkPhone   = "work"
+	HomePhone   = "home"
+
+	PersonalEmail = "personal"
+	WorkEmail     = "work"
+
+	PersonalAddress     = "personal"
+	ProfessionalAddress = "professional"
+	BillingAddress      = "billing"
+)
+
+var (
+	PhoneTypes   = []string{MobilePhone, WorkPhone, HomePhone}
+	EmailTypes   = []string{PersonalEmail, WorkEmail}
+	AddressTypes = []string{PersonalAddress, ProfessionalAddress, BillingAddress}
+)
 
 // Contacts represents a contact and all its data in the address book
 type Contact struct {
-	FirstName    string    `json:"first_name" validate:"omitempty,firstNameFormat"`
-	LastName     string    `json:"last_name" validate:"omitempty,lastNameFormat"`
-	EmailAddress string    `json:"email_address" validate:"omitempty,emailFormat"`
-	PhoneNumber  string    `json:"phone_number" validate:"omitempty,phoneNumberFormat"`
-	Addresses    Address   `json:"address,omitempty"`
-	CreatedOn    time.Time `json:"created_on"`
+	ID         string    `json:"id"`
+	UID        string    `json:"uid,omitempty"` // UID of the vCard the contact was imported from or synced as
+	FirstName  string    `json:"first_name" validate:"omitempty,firstNameFormat"`
+	LastName   string    `json:"last_name" validate:"omitempty,lastNameFormat"`
+	Phones     []Phone   `json:"phones,omitempty" validate:"dive"`
+	Emails     []Email   `json:"emails,omitempty" validate:"dive"`
+	Addresses  []Address `json:"addresses,omitempty" validate:"dive"`
+	CreatedOn  time.Time `json:"created_on"`
+	ModifiedOn time.Time `json:"modified_on"`
+}
+
+// Phone represents a phone number of a contact
+type Phone struct {
+	// The type of the phone : mobile, work, home
+	Type    string `json:"type" validate:"omitempty,oneof=mobile work home"`
+	Number  string `json:"number" validate:"required,phoneNumberFormat"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Email represents an email address of a contact
+type Email struct {
+	// The type of the email : personal, work
+	Type    string `json:"type" validate:"omitempty,oneof=personal work"`
+	Address string `json:"address" validate:"required,emailFormat"`
+	Primary bool   `json:"primary,omitempty"`
 }
 
 // Address represents a physical address for a contact
 type Address struct {
-	// The type of the address : personal, professional...
-	Type string `json:"type"`
+	// The type of the address : personal, professional, billing
+	Type string `json:"type" validate:"omitempty,oneof=personal professional billing"`
 
 	// Next fields are precisions for the address
 	Street  string `json:"street"`
 	City    string `json:"city"`
 	State   string `json:"state"`
-	Zip     string `json:"zip" validate:"omitempty,pinCodeFormat"`
+	Zip     string `json:"zip"` // Checked against the format of the country by a struct-level validation
 	Country string `json:"country"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// PrimaryPhone returns the phone flagged as primary, or the first one
+func (c Contact) PrimaryPhone() Phone {
+	for _, phone := range c.Phones {
+		if phone.Primary {
+			return phone
+		}
+	}
+	if len(c.Phones) > 0 {
+		return c.Phones[0]
+	}
+	return Phone{}
+}
+
+// PrimaryEmail returns the email flagged as primary, or the first one
+func (c Contact) PrimaryEmail() Email {
+	for _, email := range c.Emails {
+		if email.Primary {
+			return email
+		}
+	}
+	if len(c.Emails) > 0 {
+		return c.Emails[0]
+	}
+	return Email{}
+}
+
+// PrimaryAddress returns the address flagged as primary, or the first one
+func (c Contact) PrimaryAddress() Address {
+	for _, address := range c.Addresses {
+		if address.Primary {
+			return address
+		}
+	}
+	if len(c.Addresses) > 0 {
+		return c.Addresses[0]
+	}
+	return Address{}
+}
+
+// PhoneNumbers returns every phone number of the contact
+func (c Contact) PhoneNumbers() []string {
+	numbers := make([]string, 0, len(c.Phones))
+	for _, phone := range c.Phones {
+		numbers = append(numbers, phone.Number)
+	}
+	return numbers
+}
+
+// NormalizePrimary makes sure exactly one phone, email and address is flagged as primary,
+// keeping the first flagged item or flagging the first one otherwise
+func (c *Contact) NormalizePrimary() {
+	primary := primaryIndex(len(c.Phones), func(i int) bool { return c.Phones[i].Primary })
+	for i := range c.Phones {
+		c.Phones[i].Primary = i == primary
+	}
+	primary = primaryIndex(len(c.Emails), func(i int) bool { return c.Emails[i].Primary })
+	for i := range c.Emails {
+		c.Emails[i].Primary = i == primary
+	}
+	primary = primaryIndex(len(c.Addresses), func(i int) bool { return c.Addresses[i].Primary })
+	for i := range c.Addresses {
+		c.Addresses[i].Primary = i == primary
+	}
+}
+
+// primaryIndex returns the index of the first item flagged as primary, 0 when none is
+func primaryIndex(count int, isPrimary func(i int) bool) int {
+	for i := 0; i < count; i++ {
+		if isPrimary(i) {
+			return i
+		}
+	}
+	return 0
+}
+
+// PrimaryFirst returns the items with the primary one moved to the front, the others keeping their order.
+// As for PrimaryPhone, the primary item is the first one flagged as such, or the first one.
+func PrimaryFirst[T any](items []T, isPrimary func(item T) bool) []T {
+	if len(items) == 0 {
+		return nil
+	}
+	primary := primaryIndex(len(items), func(i int) bool { return isPrimary(items[i]) })
+	sorted := make([]T, 0, len(items))
+	sorted = append(sorted, items[primary])
+	sorted = append(sorted, items[:primary]...)
+	return append(sorted, items[primary+1:]...)
+}
+
+// UnmarshalJSON decodes a contact, converting the single phone_number, email_address and address
+// fields of older address books into typed lists
+func (c *Contact) UnmarshalJSON(data []byte) error {
+	type contact Contact
+	var decoded struct {
+		contact
+		PhoneNumber  string   `json:"phone_number"`
+		EmailAddress string   `json:"email_address"`
+		Address      *Address `json:"address"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*c = Contact(decoded.contact)
+	if decoded.PhoneNumber != "" && len(c.Phones) == 0 {
+		c.Phones = []Phone{{Type: MobilePhone, Number: decoded.PhoneNumber, Primary: true}}
+	}
+	if decoded.EmailAddress != "" && len(c.Emails) == 0 {
+		c.Emails = []Email{{Type: PersonalEmail, Address: decoded.EmailAddress, Primary: true}}
+	}
+	if decoded.Address != nil && *decoded.Address != (Address{}) && len(c.Addresses) == 0 {
+		address := *decoded.Address
+		address.Type = strings.ToLower(address.Type)
+		address.Primary = true
+		c.Addresses = []Address{address}
+	}
+	return nil
 }
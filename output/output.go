@@ -0,0 +1,110 @@
+// Package output prints contacts for humans, as an aligned table, or for pipelines, as JSON, JSON lines, YAML, CSV
+// or through a Go template executed for each contact.
+package output
+
+import (
+	"GoAddressBook/csvcontacts"
+	"GoAddressBook/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Names of the output formats
+const (
+	Table     = "table"
+	JSON      = "json"
+	JSONLines = "jsonl"
+	YAML      = "yaml"
+	CSV       = "csv"
+	Template  = "template"
+)
+
+// Formats lists the output formats, the template one being given as template=<go-template>
+var Formats = []string{Table, JSON, JSONLines, YAML, CSV, Template}
+
+var (
+	UnknownFormat   = errors.New("unknown output format, expected table, json, jsonl, yaml, csv or template=<go-template>")
+	InvalidTemplate = errors.New("invalid output template")
+)
+
+// Format prints contacts in one of the output formats
+type Format struct {
+	Name     string
+	Width    int // maximum width of tables, the width of the terminal when 0
+	template *template.Template
+}
+
+// Parse reads an output format such as json or template={{.FirstName}} {{.LastName}}
+func Parse(spec string) (Format, error) {
+	name, text, hasTemplate := strings.Cut(spec, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == Template && hasTemplate:
+		parsed, err := template.New(Template).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return Format{}, fmt.Errorf("%w: %s", InvalidTemplate, err)
+		}
+		return Format{Name: Template, template: parsed}, nil
+	case hasTemplate || name == Template:
+		return Format{}, UnknownFormat
+	}
+	for _, format := range Formats {
+		if name == format {
+			return Format{Name: name}, nil
+		}
+	}
+	return Format{}, UnknownFormat
+}
+
+// Write prints the contacts in the format
+func (f Format) Write(w io.Writer, contacts []models.Contact) error {
+	switch f.Name {
+	case Table:
+		return writeTable(w, contacts, f.Width)
+	case JSON:
+		if contacts == nil {
+			contacts = []models.Contact{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(contacts)
+	case JSONLines:
+		encoder := json.NewEncoder(w)
+		for _, contact := range contacts {
+			if err := encoder.Encode(contact); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAML:
+		return writeYAML(w, contacts)
+	case CSV:
+		return csvcontacts.Encode(w, contacts)
+	case Template:
+		for _, contact := range contacts {
+			if err := f.template.Execute(w, contact); err != nil {
+				return fmt.Errorf("%w: %s", InvalidTemplate, err)
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return UnknownFormat
+}
+
+// templateFuncs are the functions templates can use besides the builtin ones
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"GoAddressBook/models"
+	"fmt"
+	"golang.org/x/term"
+	"golang.org/x/text/width"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	columnGap      = "  "
+	minColumnWidth = 6
+	ellipsis       = "…"
+)
+
+// tableColumn is a column of the contacts table
+type tableColumn struct {
+	title  string
+	value  func(contact models.Contact) string
+	shrink bool // whether the values may be cut to fit the width of the terminal
+}
+
+var tableColumns = []tableColumn{
+	{"ID", func(contact models.Contact) string { return contact.ID }, false},
+	{"NAME", func(contact models.Contact) string {
+		return strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+	}, true},
+	{"PHONE", func(contact models.Contact) string {
+		return withOthers(contact.PrimaryPhone().Number, len(contact.Phones))
+	}, true},
+	{"EMAIL", func(contact models.Contact) string {
+		return withOthers(contact.PrimaryEmail().Address, len(contact.Emails))
+	}, true},
+	{"CITY", func(contact models.Contact) string { return contact.PrimaryAddress().City }, true},
+	{"STATE", func(contact models.Contact) string { return contact.PrimaryAddress().State }, true},
+}
+
+// withOthers tells how many other values a contact has besides its primary one
+func withOthers(primary string, count int) string {
+	if count > 1 {
+		return fmt.Sprintf("%s (+%d)", primary, count-1)
+	}
+	return primary
+}
+
+// writeTable prints the contacts as aligned columns, cutting the longest values when wider than maxWidth
+func writeTable(w io.Writer, contacts []models.Contact, maxWidth int) error {
+	if maxWidth <= 0 {
+		maxWidth = terminalWidth(w)
+	}
+
+	rows := make([][]string, 0, len(contacts)+1)
+	header := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		header[i] = column.title
+	}
+	rows = append(rows, header)
+	for _, contact := range contacts {
+		row := make([]string, len(tableColumns))
+		for i, column := range tableColumns {
+			row[i] = sanitize(column.value(contact))
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(tableColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			if cellWidth := displayWidth(cell); cellWidth > widths[i] {
+				widths[i] = cellWidth
+			}
+		}
+	}
+	if maxWidth > 0 {
+		fitWidths(widths, maxWidth-len(columnGap)*(len(widths)-1))
+	}
+
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			cell = truncate(cell, widths[i])
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)))
+				line.WriteString(columnGap)
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitWidths narrows the widest shrinkable columns, one cell at a time, until the columns fit in the available width
+func fitWidths(widths []int, available int) {
+	total := 0
+	for _, columnWidth := range widths {
+		total += columnWidth
+	}
+	for total > available {
+		widest := -1
+		for i, column := range tableColumns {
+			if column.shrink && widths[i] > minColumnWidth && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// terminalWidth returns the width of the terminal the table is printed on, from $COLUMNS otherwise, 0 when unknown
+func terminalWidth(w io.Writer) int {
+	if file, ok := w.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		if columns, _, err := term.GetSize(int(file.Fd())); err == nil {
+			return columns
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		return columns
+	}
+	return 0
+}
+
+// sanitize keeps cells on one line
+func sanitize(cell string) string {
+	return strings.Join(strings.Fields(cell), " ")
+}
+
+// displayWidth counts the columns a string takes on a terminal, East Asian wide characters taking two
+func displayWidth(s string) int {
+	columns := 0
+	for _, r := range s {
+		columns += runeWidth(r)
+	}
+	return columns
+}
+
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// truncate cuts the string to the given display width, ending it with an ellipsis when cut
+func truncate(s string, maxWidth int) string {
+	if displayWidth(s) <= maxWidth {
+		return s
+	}
+	var cut strings.Builder
+	columns := displayWidth(ellipsis)
+	for _, r := range s {
+		if columns+runeWidth(r) > maxWidth {
+			break
+		}
+		cut.WriteRune(r)
+		columns += runeWidth(r)
+	}
+	return cut.String() + ellipsis
+}
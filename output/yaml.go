@@ -0,0 +1,50 @@
+package output
+
+import (
+	"GoAddressBook/models"
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// writeYAML prints the contacts as a YAML list with the keys and the order of their JSON form
+func writeYAML(w io.Writer, contacts []models.Contact) error {
+	if contacts == nil {
+		contacts = []models.Contact{}
+	}
+	encoded, err := json.Marshal(contacts)
+	if err != nil {
+		return err
+	}
+	// JSON being YAML, decoding it into nodes keeps the order of the fields
+	var document yaml.Node
+	if err := yaml.Unmarshal(encoded, &document); err != nil {
+		return err
+	}
+	blockStyle(&document)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style and the quotes of the decoded JSON, except for the strings the encoder quotes
+// when marshalling them, such as yes, no, on or off which YAML 1.1 readers take for booleans
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && needsQuotes(node.Value) {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// needsQuotes tells if the encoder quotes the string when marshalling it on its own
+func needsQuotes(value string) bool {
+	encoded, err := yaml.Marshal(value)
+	return err != nil || len(encoded) > 0 && (encoded[0] == '"' || encoded[0] == '\'')
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"GoAddressBook/models"
+	"bytes"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"testing"
+)
+
+func TestWriteYAMLQuotesAmbiguousStrings(t *testing.T) {
+	tests := []struct {
+		firstName string
+		line      string
+	}{
+		{firstName: "Ada", line: "first_name: Ada"},
+		{firstName: "Yes", line: `first_name: "Yes"`},
+		{firstName: "no", line: `first_name: "no"`},
+		{firstName: "On", line: `first_name: "On"`},
+		{firstName: "y", line: `first_name: "y"`},
+		{firstName: "~", line: `first_name: "~"`},
+		{firstName: "null", line: `first_name: "null"`},
+		{firstName: "true", line: `first_name: "true"`},
+		{firstName: "0123", line: `first_name: "0123"`},
+		{firstName: "1e3", line: `first_name: "1e3"`},
+	}
+	for _, test := range tests {
+		t.Run(test.firstName, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeYAML(&out, []models.Contact{{FirstName: test.firstName}}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), test.line) {
+				t.Errorf("output doesn't contain %s:\n%s", test.line, out.String())
+			}
+
+			var decoded []map[string]interface{}
+			if err := yaml.Unmarshal(out.Bytes(), &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if name, ok := decoded[0]["first_name"].(string); !ok || name != test.firstName {
+				t.Errorf("first_name read back as %#v", decoded[0]["first_name"])
+			}
+		})
+	}
+}
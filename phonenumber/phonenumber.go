@@ -0,0 +1,131 @@
+// Package phonenumber normalizes phone numbers to E.164 and validates them against the number plan of their country
+package phonenumber
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultRegion is used for numbers written without country code when no region is given
+const DefaultRegion = "IN"
+
+var (
+	InvalidPhoneNumber = errors.New("invalid phone number")
+	UnknownRegion      = errors.New("unknown phone number region")
+)
+
+// metadata describes the number plan of a region, the national number being the number without country code and national prefix
+type metadata struct {
+	Region         string `json:"region"`
+	Code           string `json:"code"`
+	NationalPrefix string `json:"national_prefix"`
+	MinLength      int    `json:"min_length"`
+	MaxLength      int    `json:"max_length"`
+	Pattern        string `json:"pattern"`
+	pattern        *regexp.Regexp
+}
+
+//go:embed metadata.json
+var metadataJson []byte
+
+var (
+	regions = map[string]*metadata{}   // Number plans by ISO 3166 region code
+	codes   = map[string][]*metadata{} // Number plans by country calling code, several regions sharing a code
+)
+
+func init() {
+	var plans []*metadata
+	if err := json.Unmarshal(metadataJson, &plans); err != nil {
+		panic("invalid phone number metadata " + err.Error())
+	}
+	for _, plan := range plans {
+		plan.pattern = regexp.MustCompile("^(?:" + plan.Pattern + ")$")
+		regions[plan.Region] = plan
+		codes[plan.Code] = append(codes[plan.Code], plan)
+	}
+}
+
+// Normalize converts a number written in any format, such as "088045 60520", "+91 88045-60520" or "0091 8804560520",
+// to E.164 such as "+918804560520". Numbers without country code are read as numbers of the region.
+func Normalize(number, region string) (string, error) {
+	digits, international := stripFormatting(number)
+	if digits == "" {
+		return "", InvalidPhoneNumber
+	}
+	if international {
+		return normalizeInternational(digits)
+	}
+
+	if region == "" {
+		region = DefaultRegion
+	}
+	plan, found := regions[strings.ToUpper(region)]
+	if !found {
+		return "", UnknownRegion
+	}
+	if plan.valid(digits) {
+		return "+" + plan.Code + digits, nil
+	}
+	if national := strings.TrimPrefix(digits, plan.NationalPrefix); plan.NationalPrefix != "" && plan.valid(national) {
+		return "+" + plan.Code + national, nil
+	}
+	// Numbers are often written with the country code but without the leading +
+	if strings.HasPrefix(digits, plan.Code) && plan.valid(digits[len(plan.Code):]) {
+		return "+" + digits, nil
+	}
+	return "", InvalidPhoneNumber
+}
+
+// IsValid tells whether the number, in any format, is a valid number of its country
+func IsValid(number, region string) bool {
+	_, err := Normalize(number, region)
+	return err == nil
+}
+
+// Region returns the region of a number normalized to E.164, the first listed one when several share its country code
+func Region(e164 string) string {
+	digits := strings.TrimPrefix(e164, "+")
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		for _, plan := range codes[digits[:length]] {
+			if plan.valid(digits[length:]) {
+				return plan.Region
+			}
+		}
+	}
+	return ""
+}
+
+// stripFormatting keeps only the digits of the number, telling whether it starts with + or the 00 international prefix
+func stripFormatting(number string) (string, bool) {
+	number = strings.TrimSpace(number)
+	international := strings.HasPrefix(number, "+")
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	if !international && strings.HasPrefix(digits, "00") {
+		return digits[2:], true
+	}
+	return digits, international
+}
+
+// normalizeInternational finds the country calling code, one to three digits long, and checks the rest against its plans
+func normalizeInternational(digits string) (string, error) {
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		for _, plan := range codes[digits[:length]] {
+			if plan.valid(digits[length:]) {
+				return "+" + digits, nil
+			}
+		}
+	}
+	return "", InvalidPhoneNumber
+}
+
+func (plan *metadata) valid(national string) bool {
+	return len(national) >= plan.MinLength && len(national) <= plan.MaxLength && plan.pattern.MatchString(national)
+}
@@ -0,0 +1,66 @@
+package phonenumber
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func ExampleNormalize() {
+	for _, number := range []string{"088045 60520", "+91 88045-60520", "0091 8804560520", "91 8804560520"} {
+		e164, _ := Normalize(number, "")
+		fmt.Println(e164)
+	}
+	// Output:
+	// +918804560520
+	// +918804560520
+	// +918804560520
+	// +918804560520
+}
+
+func ExampleNormalize_region() {
+	for _, number := range []string{"(202) 555-0143", "1 202 555 0143", "+44 20 7946 0000"} {
+		e164, _ := Normalize(number, "us")
+		fmt.Println(e164)
+	}
+	// Output:
+	// +12025550143
+	// +12025550143
+	// +442079460000
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	for _, test := range []struct {
+		number, region string
+		err            error
+	}{
+		{"", "", InvalidPhoneNumber},
+		{"call me", "", InvalidPhoneNumber},
+		{"12345", "", InvalidPhoneNumber},
+		{"(202) 155-0143", "US", InvalidPhoneNumber}, // exchange codes can't start with 1
+		{"+999 1234567", "", InvalidPhoneNumber},     // unassigned country code
+		{"8804560520", "XX", UnknownRegion},
+	} {
+		if _, err := Normalize(test.number, test.region); !errors.Is(err, test.err) {
+			t.Errorf("Normalize(%q, %q) error = %v, want %v", test.number, test.region, err, test.err)
+		}
+		if IsValid(test.number, test.region) {
+			t.Errorf("IsValid(%q, %q) = true", test.number, test.region)
+		}
+	}
+}
+
+func TestRegion(t *testing.T) {
+	for e164, want := range map[string]string{
+		"+918804560520": "IN",
+		"+12025550143":  "US", // listed before CA, which shares its code
+		"+442079460000": "GB",
+		"+353112345678": "IE",
+		"+9991234567":   "",
+		"":              "",
+	} {
+		if got := Region(e164); got != want {
+			t.Errorf("Region(%q) = %q, want %q", e164, got, want)
+		}
+	}
+}
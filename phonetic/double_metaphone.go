@@ -0,0 +1,589 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MetaphoneLength is the length of the codes returned by DoubleMetaphone
+const MetaphoneLength = 4
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone codes of a word,
+// following Lawrence Philips' algorithm. Both codes are equal when the word has a single pronunciation.
+func DoubleMetaphone(word string) (string, string) {
+	m := newMetaphone(word)
+	if m.length == 0 {
+		return "", ""
+	}
+	m.encode()
+	return m.primary.result(), m.alternate.result()
+}
+
+type code struct {
+	builder strings.Builder
+}
+
+func (c *code) add(value string) {
+	if c.builder.Len() < MetaphoneLength {
+		c.builder.WriteString(value)
+	}
+}
+
+func (c *code) full() bool {
+	return c.builder.Len() >= MetaphoneLength
+}
+
+func (c *code) result() string {
+	value := c.builder.String()
+	if len(value) > MetaphoneLength {
+		return value[:MetaphoneLength]
+	}
+	return value
+}
+
+type metaphone struct {
+	value              []rune
+	length             int
+	last               int
+	slavoGermanic      bool
+	primary, alternate code
+}
+
+func newMetaphone(word string) *metaphone {
+	var value []rune
+	for _, r := range strings.ToUpper(strings.TrimSpace(word)) {
+		if unicode.IsLetter(r) || r == ' ' {
+			value = append(value, r)
+		}
+	}
+	m := &metaphone{value: value, length: len(value), last: len(value) - 1}
+	upper := string(value)
+	m.slavoGermanic = strings.Contains(upper, "W") || strings.Contains(upper, "K") ||
+		strings.Contains(upper, "CZ") || strings.Contains(upper, "WITZ")
+	return m
+}
+
+// add appends the same code to both the primary and alternate encodings
+func (m *metaphone) add(value string) {
+	m.primary.add(value)
+	m.alternate.add(value)
+}
+
+// addBoth appends different codes to the primary and alternate encodings
+func (m *metaphone) addBoth(primary, alternate string) {
+	m.primary.add(primary)
+	m.alternate.add(alternate)
+}
+
+func (m *metaphone) charAt(index int) rune {
+	if index < 0 || index >= m.length {
+		return 0
+	}
+	return m.value[index]
+}
+
+// contains tells whether the substring of the given length at start is one of the options
+func (m *metaphone) contains(start, length int, options ...string) bool {
+	if start < 0 || start+length > m.length {
+		return false
+	}
+	target := string(m.value[start : start+length])
+	for _, option := range options {
+		if option == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) isVowel(index int) bool {
+	return strings.ContainsRune("AEIOUY", m.charAt(index))
+}
+
+func (m *metaphone) encode() {
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+	if m.charAt(0) == 'X' {
+		m.add("S")
+		index = 1
+	}
+
+	for index < m.length && !(m.primary.full() && m.alternate.full()) {
+		switch m.charAt(index) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, 'B')
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, 'F')
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, 'K')
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.conditionM0(index) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, 'N')
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			index = m.handleP(index)
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, 'Q')
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, 'V')
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+}
+
+// skip moves past the letter, and past its double when it's repeated
+func (m *metaphone) skip(index int, letter rune) int {
+	if m.charAt(index+1) == letter {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		m.addBoth("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.charAt(0) == 'M'):
+		return m.handleCC(index)
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.addBoth("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	default:
+		m.add("K")
+		if m.contains(index+1, 2, " C", " Q", " G") {
+			return index + 3
+		}
+		if m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI") {
+			return index + 2
+		}
+		return index + 1
+	}
+}
+
+func (m *metaphone) conditionC0(index int) bool {
+	if m.contains(index, 4, "CHIA") {
+		return true
+	}
+	if index <= 1 || m.isVowel(index-2) || !m.contains(index-1, 3, "ACH") {
+		return false
+	}
+	c := m.charAt(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		m.addBoth("K", "X")
+	case m.conditionCH0(index), m.conditionCH1(index):
+		m.add("K")
+	case index > 0:
+		if m.contains(0, 2, "MC") {
+			m.add("K")
+		} else {
+			m.addBoth("X", "K")
+		}
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.contains(index+1, 5, "HARAC", "HARIS") && !m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.contains(0, 5, "CHORE")
+}
+
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") ||
+		m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == m.last))
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+		if (index == 1 && m.charAt(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+			m.add("KS")
+		} else {
+			m.add("X")
+		}
+		return index + 3
+	}
+	m.add("K")
+	return index + 2
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.contains(index, 2, "DG"):
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			m.add("J")
+			return index + 3
+		}
+		m.add("TK")
+		return index + 2
+	case m.contains(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	default:
+		m.add("T")
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.charAt(index+1) == 'H':
+		return m.handleGH(index)
+	case m.charAt(index+1) == 'N':
+		switch {
+		case index == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.addBoth("KN", "N")
+		case !m.contains(index+2, 2, "EY") && m.charAt(index+1) != 'Y' && !m.slavoGermanic:
+			m.addBoth("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		m.addBoth("KL", "L")
+		return index + 2
+	case index == 0 && (m.charAt(index+1) == 'Y' ||
+		m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.addBoth("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.charAt(index+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") && !m.contains(index-1, 3, "RGY", "OGY"):
+		m.addBoth("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") || m.contains(index+1, 2, "ET"):
+			m.add("K")
+		case m.contains(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.addBoth("J", "K")
+		}
+		return index + 2
+	case m.charAt(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	default:
+		m.add("K")
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !m.isVowel(index-1):
+		m.add("K")
+	case index == 0:
+		if m.charAt(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// Silent, as in "bough" or "night"
+	case index > 2 && m.charAt(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		m.add("F")
+	case index > 0 && m.charAt(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	if (index == 0 || m.isVowel(index-1)) && m.isVowel(index+1) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		if (index == 0 && m.charAt(index+4) == ' ') || m.length == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.addBoth("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.addBoth("J", "A")
+	case m.isVowel(index-1) && !m.slavoGermanic && (m.charAt(index+1) == 'A' || m.charAt(index+1) == 'O'):
+		m.addBoth("J", "H")
+	case index == m.last:
+		m.addBoth("J", "")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, 'J')
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.charAt(index+1) == 'L' {
+		if m.conditionL0(index) {
+			m.addBoth("L", "")
+		} else {
+			m.add("L")
+		}
+		return index + 2
+	}
+	m.add("L")
+	return index + 1
+}
+
+func (m *metaphone) conditionL0(index int) bool {
+	if index == m.length-3 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE") {
+		return true
+	}
+	return (m.contains(m.last-1, 2, "AS", "OS") || m.contains(m.last, 1, "A", "O")) &&
+		m.contains(index-1, 4, "ALLE")
+}
+
+func (m *metaphone) conditionM0(index int) bool {
+	if m.charAt(index+1) == 'M' {
+		return true
+	}
+	return m.contains(index-1, 3, "UMB") && (index+1 == m.last || m.contains(index+2, 2, "ER"))
+}
+
+func (m *metaphone) handleP(index int) int {
+	if m.charAt(index+1) == 'H' {
+		m.add("F")
+		return index + 2
+	}
+	m.add("P")
+	if m.contains(index+1, 1, "P", "B") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleR(index int) int {
+	if index == m.last && !m.slavoGermanic && m.contains(index-2, 2, "IE") && !m.contains(index-4, 2, "ME", "MA") {
+		m.addBoth("", "R")
+	} else {
+		m.add("R")
+	}
+	return m.skip(index, 'R')
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.addBoth("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.addBoth("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		m.addBoth("S", "X")
+		if m.contains(index+1, 1, "Z") {
+			return index + 2
+		}
+		return index + 1
+	case m.contains(index, 2, "SC"):
+		return m.handleSC(index)
+	default:
+		if index == m.last && m.contains(index-2, 2, "AI", "OI") {
+			m.addBoth("", "S")
+		} else {
+			m.add("S")
+		}
+		if m.contains(index+1, 1, "S", "Z") {
+			return index + 2
+		}
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.charAt(index+2) == 'H':
+		switch {
+		case m.contains(index+3, 2, "ER", "EN"):
+			m.addBoth("X", "SK")
+		case m.contains(index+3, 2, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case index == 0 && !m.isVowel(3) && m.charAt(3) != 'W':
+			m.addBoth("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.contains(index, 4, "TION"), m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH") || m.contains(index, 3, "TTH"):
+		if m.contains(index+2, 2, "OM", "AM") || m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") {
+			m.add("T")
+		} else {
+			m.addBoth("0", "T")
+		}
+		return index + 2
+	default:
+		m.add("T")
+		if m.contains(index+1, 1, "T", "D") {
+			return index + 2
+		}
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		m.add("R")
+		return index + 2
+	case index == 0 && (m.isVowel(index+1) || m.contains(index, 2, "WH")):
+		if m.isVowel(index + 1) {
+			m.addBoth("A", "F")
+		} else {
+			m.add("A")
+		}
+		return index + 1
+	case (index == m.last && m.isVowel(index-1)) ||
+		m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		m.addBoth("", "F")
+		return index + 1
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		m.addBoth("TS", "FX")
+		return index + 4
+	default:
+		return index + 1
+	}
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	if !(index == m.last && (m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	if m.contains(index+1, 1, "C", "X") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.charAt(index+1) == 'H' {
+		m.add("J")
+		return index + 2
+	}
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.charAt(index-1) != 'T') {
+		m.addBoth("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, 'Z')
+}
@@ -0,0 +1,48 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// indicReplacements folds the romanization variants commonly found in Indian names onto a single spelling,
+// longest patterns first
+var indicReplacements = []struct {
+	from, to string
+}{
+	{"chh", "c"}, {"ksh", "x"},
+	{"aa", "a"}, {"ee", "i"}, {"ii", "i"}, {"oo", "u"}, {"uu", "u"}, {"ou", "u"}, {"au", "o"},
+	{"bh", "b"}, {"ch", "c"}, {"dh", "d"}, {"gh", "g"}, {"jh", "j"}, {"kh", "k"}, {"ph", "f"},
+	{"sh", "s"}, {"th", "t"}, {"ck", "k"},
+	{"q", "k"}, {"w", "v"}, {"z", "j"}, {"y", "i"},
+}
+
+// IndicKey returns a transliteration-aware phonetic key suited for Indian names: aspirated consonants
+// and long vowels are folded, doubled letters collapsed and vowels after the first letter dropped,
+// so that "Pintu" and "Pintoo" or "Mohammad" and "Muhammed" share the same key
+func IndicKey(word string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsLetter(r) && r < unicode.MaxASCII {
+			letters.WriteRune(r)
+		}
+	}
+	folded := letters.String()
+	for _, replacement := range indicReplacements {
+		folded = strings.ReplaceAll(folded, replacement.from, replacement.to)
+	}
+
+	var key strings.Builder
+	var previous rune
+	for i, r := range folded {
+		if r == previous {
+			continue
+		}
+		previous = r
+		if i > 0 && strings.ContainsRune("aeiou", r) {
+			continue
+		}
+		key.WriteRune(r)
+	}
+	return key.String()
+}
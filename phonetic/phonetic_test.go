@@ -0,0 +1,86 @@
+package phonetic
+
+import "testing"
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word               string
+		primary, alternate string
+	}{
+		{"Smith", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Schneider", "XNTR", "SNTR"},
+		{"Thompson", "TMPS", "TMPS"},
+		{"Thomas", "TMS", "TMS"},
+		{"Catherine", "K0RN", "KTRN"},
+		{"Katherine", "K0RN", "KTRN"},
+		{"Michael", "MKL", "MXL"},
+		{"Jose", "HS", "HS"},
+		{"Jones", "JNS", "ANS"},
+		{"Xavier", "SF", "SFR"},
+		{"Caesar", "SSR", "SSR"},
+		{"Knight", "NT", "NT"},
+		{"Wright", "RT", "RT"},
+		{"Williams", "ALMS", "FLMS"},
+		{"Gallegos", "KLKS", "KKS"},
+		{"Tagliaro", "TKLR", "TLR"},
+		{"Cabrillo", "KPRL", "KPR"},
+		{"Bajador", "PJTR", "PHTR"},
+		{"Arnow", "ARN", "ARNF"},
+		{"Gough", "KF", "KF"},
+		{"Dumb", "TM", "TM"},
+		{"O'Brien", "APRN", "APRN"},
+		{"Raghavan", "RKFN", "RKFN"},
+		{"", "", ""},
+		{"  ", "", ""},
+	}
+	for _, test := range tests {
+		primary, alternate := DoubleMetaphone(test.word)
+		if primary != test.primary || alternate != test.alternate {
+			t.Errorf("DoubleMetaphone(%q) = %s, %s, want %s, %s", test.word, primary, alternate, test.primary, test.alternate)
+		}
+	}
+}
+
+func TestIndicKey(t *testing.T) {
+	// Romanizations of the same name share a key
+	for _, spellings := range [][]string{
+		{"Pintu", "Pintoo"},
+		{"Mohammad", "Muhammed", "Mohammed"},
+		{"Lakshmi", "Laxmi"},
+		{"Bhaskar", "Baskar"},
+		{"Deepak", "Dipak"},
+		{"Srinivas", "Shrinivas"},
+		{"Zubair", "Jubair"},
+		{"Vijay", "Wijay"},
+		{"Anand", "Aanand"},
+		{"Gaurav", "Gourav"},
+	} {
+		want := IndicKey(spellings[0])
+		for _, spelling := range spellings[1:] {
+			if got := IndicKey(spelling); got != want {
+				t.Errorf("IndicKey(%q) = %q, want %q as for %q", spelling, got, want, spellings[0])
+			}
+		}
+	}
+
+	for word, want := range map[string]string{
+		"Pintoo":    "pnt",
+		"Lakshmi":   "lxm",
+		"Bhaskar":   "bskr",
+		"Shrinivas": "srnvs",
+		"Priyanka":  "prnk",
+		"":          "",
+	} {
+		if got := IndicKey(word); got != want {
+			t.Errorf("IndicKey(%q) = %q, want %q", word, got, want)
+		}
+	}
+
+	// Different names keep different keys
+	for _, names := range [][2]string{{"Ravi", "Rahul"}, {"Anand", "Anil"}, {"Deepak", "Dinesh"}} {
+		if IndicKey(names[0]) == IndicKey(names[1]) {
+			t.Errorf("%q and %q share the key %q", names[0], names[1], IndicKey(names[0]))
+		}
+	}
+}
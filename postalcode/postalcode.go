@@ -0,0 +1,68 @@
+// Package postalcode validates postal codes against the format of their country
+package postalcode
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// DefaultCountry is the country of addresses without one when no country is configured
+const DefaultCountry = "IN"
+
+// rule is the postal code format of a country, an empty pattern meaning the country has no postal codes
+type rule struct {
+	Country   string   `json:"country"`
+	Names     []string `json:"names"`
+	Pattern   string   `json:"pattern"`
+	Forbidden string   `json:"forbidden,omitempty"` // Codes matching the pattern but never assigned, such as Indian PINs ending in 000
+	pattern   *regexp.Regexp
+	forbidden *regexp.Regexp
+}
+
+//go:embed rules.json
+var rulesJson []byte
+
+// rules maps the lowercase ISO 3166 code and names of each country to its rule
+var rules = map[string]*rule{}
+
+func init() {
+	var table []*rule
+	if err := json.Unmarshal(rulesJson, &table); err != nil {
+		panic("invalid postal code rules " + err.Error())
+	}
+	for _, r := range table {
+		r.pattern = regexp.MustCompile("(?i)^(?:" + r.Pattern + ")$")
+		if r.Forbidden != "" {
+			r.forbidden = regexp.MustCompile("(?i)^(?:" + r.Forbidden + ")$")
+		}
+		rules[strings.ToLower(r.Country)] = r
+		for _, name := range r.Names {
+			rules[name] = r
+		}
+	}
+}
+
+// CountryCode returns the ISO 3166 code of a country given by code or by name, such as "IN" for "India"
+func CountryCode(country string) (string, bool) {
+	r, found := rules[strings.ToLower(strings.TrimSpace(country))]
+	if !found {
+		return "", false
+	}
+	return r.Country, true
+}
+
+// IsValid tells whether the postal code has the format of the country, given by ISO 3166 code or by name.
+// Codes of countries missing from the rule table are accepted, as their format can't be checked.
+func IsValid(country, code string) bool {
+	r, found := rules[strings.ToLower(strings.TrimSpace(country))]
+	if !found {
+		return true
+	}
+	code = strings.TrimSpace(code)
+	if r.Pattern == "" {
+		return code == ""
+	}
+	return r.pattern.MatchString(code) && (r.forbidden == nil || !r.forbidden.MatchString(code))
+}
@@ -0,0 +1,47 @@
+package postalcode
+
+import "testing"
+
+func TestIsValid(t *testing.T) {
+	valid := map[string][]string{
+		"IN":             {"560001", " 110011 "},
+		"india":          {"673310"},
+		"US":             {"10001", "10001-1234"},
+		"GB":             {"SW1Y 4JH", "sw1y4jh", "EC1A 1BB"},
+		"United Kingdom": {"GIR 0AA"},
+		"CA":             {"K1A 0B1"},
+		"NL":             {"1234 AB"},
+		"UAE":            {""},   // no postal codes
+		"Atlantis":       {"?!"}, // unknown countries aren't checked
+	}
+	invalid := map[string][]string{
+		"IN":  {"560000", "56001", "0560001"},
+		"usa": {"1000", "10001-12"},
+		"GB":  {"12345", "QQ1 1AA"},
+		"DE":  {"00123"},
+		"NL":  {"1234 SS"},
+		"AE":  {"12345"},
+	}
+	for country, codes := range valid {
+		for _, code := range codes {
+			if !IsValid(country, code) {
+				t.Errorf("%s postal code %q rejected", country, code)
+			}
+		}
+	}
+	for country, codes := range invalid {
+		for _, code := range codes {
+			if IsValid(country, code) {
+				t.Errorf("%s postal code %q accepted", country, code)
+			}
+		}
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	for country, want := range map[string]string{"in": "IN", " Bharat ": "IN", "España": "ES", "Atlantis": ""} {
+		if got, found := CountryCode(country); got != want || found != (want != "") {
+			t.Errorf("CountryCode(%q) = %q, %v, want %q", country, got, found, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package query
+
+import (
+	"GoAddressBook/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ID          = "id"
+	FirstName   = "first_name"
+	LastName    = "last_name"
+	Name        = "name"
+	Phone       = "phone"
+	PhoneType   = "phone_type"
+	Email       = "email"
+	EmailType   = "email_type"
+	AddressType = "address_type"
+	Street      = "street"
+	City        = "city"
+	State       = "state"
+	Zip         = "zip"
+	Country     = "country"
+	CreatedOn   = "created_on"
+)
+
+var fields = map[string]func(contact models.Contact) []string{
+	ID:        func(c models.Contact) []string { return []string{c.ID} },
+	FirstName: func(c models.Contact) []string { return []string{c.FirstName} },
+	LastName:  func(c models.Contact) []string { return []string{c.LastName} },
+	Name:      func(c models.Contact) []string { return []string{strings.TrimSpace(c.FirstName + " " + c.LastName)} },
+	Phone:     func(c models.Contact) []string { return c.PhoneNumbers() },
+	PhoneType: func(c models.Contact) []string {
+		return collect(len(c.Phones), func(i int) string { return c.Phones[i].Type })
+	},
+	Email: func(c models.Contact) []string {
+		return collect(len(c.Emails), func(i int) string { return c.Emails[i].Address })
+	},
+	EmailType: func(c models.Contact) []string {
+		return collect(len(c.Emails), func(i int) string { return c.Emails[i].Type })
+	},
+	AddressType: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].Type })
+	},
+	Street: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].Street })
+	},
+	City: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].City })
+	},
+	State: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].State })
+	},
+	Zip: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].Zip })
+	},
+	Country: func(c models.Contact) []string {
+		return collect(len(c.Addresses), func(i int) string { return c.Addresses[i].Country })
+	},
+	CreatedOn: func(c models.Contact) []string { return []string{c.CreatedOn.Format(time.RFC3339Nano)} },
+}
+
+// IsField tells whether the name is a field queries can filter and order on
+func IsField(name string) bool {
+	_, found := fields[name]
+	return found
+}
+
+// Match tells whether the contact satisfies the condition of the query
+func (q *Query) Match(contact models.Contact) bool {
+	return q.Where == nil || evaluate(q.Where, contact)
+}
+
+// Run filters, sorts and paginates the contacts according to the query
+func (q *Query) Run(contacts []models.Contact) []models.Contact {
+	results := make([]models.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		if q.Match(contact) {
+			results = append(results, contact)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		for _, ordering := range q.OrderBy {
+			comparison := compareField(ordering.Field, results[i], results[j])
+			if comparison == 0 {
+				continue
+			}
+			if ordering.Descending {
+				return comparison > 0
+			}
+			return comparison < 0
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if q.Offset >= len(results) {
+		return []models.Contact{}
+	}
+	results = results[q.Offset:]
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+func evaluate(expression Expression, contact models.Contact) bool {
+	switch e := expression.(type) {
+	case And:
+		return evaluate(e.Left, contact) && evaluate(e.Right, contact)
+	case Or:
+		return evaluate(e.Left, contact) || evaluate(e.Right, contact)
+	case Not:
+		return !evaluate(e.Operand, contact)
+	case Comparison:
+		return compare(e, contact)
+	default:
+		return false
+	}
+}
+
+// compare evaluates the comparison on every value of a multi-valued field, matching when any does.
+// != is the negation of =, so it only matches when no value equals the literal.
+func compare(comparison Comparison, contact models.Contact) bool {
+	if comparison.Operator == "!=" {
+		return !compare(Comparison{Field: comparison.Field, Operator: "=", Value: comparison.Value}, contact)
+	}
+	if comparison.Field == CreatedOn {
+		return compareTime(comparison, contact.CreatedOn)
+	}
+
+	literal := strings.ToLower(comparison.Value)
+	for _, value := range fields[comparison.Field](contact) {
+		value = strings.ToLower(value)
+		var matched bool
+		switch comparison.Operator {
+		case "=":
+			matched = value == literal
+		case "~":
+			matched = strings.Contains(value, literal)
+		case "<":
+			matched = value < literal
+		case "<=":
+			matched = value <= literal
+		case ">":
+			matched = value > literal
+		case ">=":
+			matched = value >= literal
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// compareTime compares dates in UTC, a literal without time standing for the whole UTC day
+func compareTime(comparison Comparison, value time.Time) bool {
+	literal, err := parseTime(comparison.Value)
+	if err != nil {
+		return false
+	}
+	value = value.UTC()
+	dayOnly := len(comparison.Value) == len(time.DateOnly)
+	if dayOnly {
+		value = time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	switch comparison.Operator {
+	case "=":
+		return value.Equal(literal)
+	case "<":
+		return value.Before(literal)
+	case "<=":
+		return !value.After(literal)
+	case ">":
+		return value.After(literal)
+	case ">=":
+		return !value.Before(literal)
+	case "~":
+		return strings.Contains(value.Format(time.RFC3339), comparison.Value)
+	}
+	return false
+}
+
+// compareField orders two contacts on a field, comparing the first value of multi-valued fields
+func compareField(field string, left, right models.Contact) int {
+	if field == CreatedOn {
+		switch {
+		case left.CreatedOn.Before(right.CreatedOn):
+			return -1
+		case left.CreatedOn.After(right.CreatedOn):
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(first(fields[field](left)), first(fields[field](right)))
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.ToLower(values[0])
+}
+
+func collect(count int, value func(i int) string) []string {
+	values := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		values = append(values, value(i))
+	}
+	return values
+}
+
+func parseTime(value string) (time.Time, error) {
+	if len(value) == len(time.DateOnly) {
+		return time.Parse(time.DateOnly, value)
+	}
+	return time.Parse(time.RFC3339, value)
+}
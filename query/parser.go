@@ -0,0 +1,333 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed contact query:
+//
+//	[condition] [ORDER BY field [ASC|DESC], ...] [LIMIT n] [OFFSET n]
+//
+// where conditions compare fields with =, !=, <, <=, >, >= or ~ (contains), combined with AND, OR, NOT and parentheses,
+// e.g. state = "karnataka" AND created_on > 2023-01-01 ORDER BY last_name LIMIT 20
+type Query struct {
+	Where   Expression // nil matches every contact
+	OrderBy []Ordering
+	Limit   int // 0 for no limit
+	Offset  int
+}
+
+// Ordering sorts the results on a field
+type Ordering struct {
+	Field      string
+	Descending bool
+}
+
+// Expression is a condition on a contact
+type Expression interface {
+	String() string
+}
+
+// And is true when both sides are
+type And struct {
+	Left, Right Expression
+}
+
+// Or is true when either side is
+type Or struct {
+	Left, Right Expression
+}
+
+// Not negates its operand
+type Not struct {
+	Operand Expression
+}
+
+// Comparison compares a field of the contact with a literal value
+type Comparison struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+func (e And) String() string        { return fmt.Sprintf("(%s AND %s)", e.Left, e.Right) }
+func (e Or) String() string         { return fmt.Sprintf("(%s OR %s)", e.Left, e.Right) }
+func (e Not) String() string        { return fmt.Sprintf("NOT %s", e.Operand) }
+func (e Comparison) String() string { return fmt.Sprintf("%s %s %q", e.Field, e.Operator, e.Value) }
+
+const (
+	identifierToken = iota
+	stringToken
+	operatorToken
+	openToken
+	closeToken
+	commaToken
+)
+
+type token struct {
+	kind     int
+	text     string
+	position int
+}
+
+// Parse reads a query, returning an error pointing at the offending position when it's malformed
+func Parse(text string) (*Query, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	query := &Query{}
+
+	if !p.atEnd() && !p.isKeyword("ORDER") && !p.isKeyword("LIMIT") && !p.isKeyword("OFFSET") {
+		if query.Where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("ORDER") {
+		p.position++
+		if !p.isKeyword("BY") {
+			return nil, p.errorf("expected BY after ORDER")
+		}
+		p.position++
+		if query.OrderBy, err = p.parseOrderings(); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("LIMIT") {
+		p.position++
+		if query.Limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("OFFSET") {
+		p.position++
+		if query.Offset, err = p.parseCount("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+	if !p.atEnd() {
+		return nil, p.errorf("unexpected %q", p.tokens[p.position].text)
+	}
+	return query, nil
+}
+
+// lex splits the query into identifiers, quoted strings, operators, parentheses and commas
+func lex(text string) ([]token, error) {
+	var tokens []token
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken, text: "(", position: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")", position: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: commaToken, text: ",", position: i})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			var value strings.Builder
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				value.WriteRune(runes[end])
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			tokens = append(tokens, token{kind: stringToken, text: value.String(), position: i})
+			i = end + 1
+		case strings.ContainsRune("=!<>~", r):
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != '=' && r != '~' {
+				operator += "="
+			}
+			if operator == "!" {
+				return nil, fmt.Errorf("unknown operator %q at position %d", operator, i+1)
+			}
+			tokens = append(tokens, token{kind: operatorToken, text: operator, position: i})
+			i += len(operator)
+		default:
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, token{kind: identifierToken, text: string(runes[start:i]), position: start})
+		}
+	}
+	return tokens, nil
+}
+
+// isWordRune tells whether the rune can be part of a bare word such as a field name, a date or a phone number
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:@+", r)
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) atEnd() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	return !p.atEnd() && p.tokens[p.position].kind == identifierToken && strings.EqualFold(p.tokens[p.position].text, keyword)
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if !p.atEnd() {
+		return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.tokens[p.position].position+1)
+	}
+	return fmt.Errorf("%s at end of query", fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.position++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.position++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expression, error) {
+	if p.isKeyword("NOT") {
+		p.position++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expression, error) {
+	if p.atEnd() {
+		return nil, p.errorf("expected a condition")
+	}
+	if p.tokens[p.position].kind == openToken {
+		p.position++
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.atEnd() || p.tokens[p.position].kind != closeToken {
+			return nil, p.errorf("expected )")
+		}
+		p.position++
+		return expression, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	field := p.tokens[p.position]
+	if field.kind != identifierToken {
+		return nil, p.errorf("expected a field name")
+	}
+	name := strings.ToLower(field.text)
+	if !IsField(name) {
+		return nil, p.errorf("unknown field %q", field.text)
+	}
+	p.position++
+
+	if p.atEnd() || p.tokens[p.position].kind != operatorToken {
+		return nil, p.errorf("expected a comparison operator after %s", field.text)
+	}
+	operator := p.tokens[p.position].text
+	p.position++
+
+	if p.atEnd() || (p.tokens[p.position].kind != identifierToken && p.tokens[p.position].kind != stringToken) {
+		return nil, p.errorf("expected a value after %s %s", field.text, operator)
+	}
+	value := p.tokens[p.position]
+	p.position++
+
+	comparison := Comparison{Field: name, Operator: operator, Value: value.text}
+	if name == CreatedOn {
+		if _, err := parseTime(value.text); err != nil {
+			return nil, fmt.Errorf("invalid date %q at position %d, expected YYYY-MM-DD", value.text, value.position+1)
+		}
+	}
+	return comparison, nil
+}
+
+func (p *parser) parseOrderings() ([]Ordering, error) {
+	var orderings []Ordering
+	for {
+		if p.atEnd() || p.tokens[p.position].kind != identifierToken {
+			return nil, p.errorf("expected a field name to order by")
+		}
+		name := strings.ToLower(p.tokens[p.position].text)
+		if !IsField(name) {
+			return nil, p.errorf("unknown field %q", p.tokens[p.position].text)
+		}
+		p.position++
+
+		ordering := Ordering{Field: name}
+		if p.isKeyword("DESC") {
+			ordering.Descending = true
+			p.position++
+		} else if p.isKeyword("ASC") {
+			p.position++
+		}
+		orderings = append(orderings, ordering)
+
+		if p.atEnd() || p.tokens[p.position].kind != commaToken {
+			return orderings, nil
+		}
+		p.position++
+	}
+}
+
+func (p *parser) parseCount(keyword string) (int, error) {
+	if p.atEnd() {
+		return 0, p.errorf("expected a number after %s", keyword)
+	}
+	count, err := strconv.Atoi(p.tokens[p.position].text)
+	if err != nil || count < 0 {
+		return 0, p.errorf("expected a positive number after %s", keyword)
+	}
+	p.position++
+	return count, nil
+}
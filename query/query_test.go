@@ -0,0 +1,131 @@
+package query
+
+import (
+	"GoAddressBook/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWhere(t *testing.T) {
+	for text, want := range map[string]string{
+		`first_name = "Ada"`:                                 `first_name = "Ada"`,
+		`City ~ 'ban' and not state != karnataka`:            `(city ~ "ban" AND NOT state != "karnataka")`,
+		`email = "a\"b" OR phone = +911234 AND zip >= 56`:    `(email = "a\"b" OR (phone = "+911234" AND zip >= "56"))`,
+		`(name ~ a OR name ~ b) AND created_on < 2023-01-01`: `((name ~ "a" OR name ~ "b") AND created_on < "2023-01-01")`,
+		`NOT NOT (country = IN)`:                             `NOT NOT country = "IN"`,
+	} {
+		query, err := Parse(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if got := query.Where.String(); got != want {
+			t.Errorf("%s: parsed as %s, want %s", text, got, want)
+		}
+	}
+}
+
+func TestParseClauses(t *testing.T) {
+	query, err := Parse(`ORDER BY last_name DESC, first_name asc LIMIT 20 OFFSET 40`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Query{
+		OrderBy: []Ordering{{Field: LastName, Descending: true}, {Field: FirstName}},
+		Limit:   20,
+		Offset:  40,
+	}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("got %+v, want %+v", query, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for text, want := range map[string]string{
+		`nickname = "Ada"`:       `unknown field "nickname" at position 1`,
+		`first_name "Ada"`:       "expected a comparison operator after first_name at position 12",
+		`first_name =`:           "expected a value after first_name = at end of query",
+		`first_name ! "Ada"`:     `unknown operator "!" at position 12`,
+		`first_name = "Ada`:      "unterminated string at position 14",
+		`(city = Pune`:           "expected ) at end of query",
+		`city = Pune AND`:        "expected a condition at end of query",
+		`created_on > yesterday`: `invalid date "yesterday" at position 14, expected YYYY-MM-DD`,
+		`ORDER last_name`:        "expected BY after ORDER at position 7",
+		`ORDER BY age`:           `unknown field "age" at position 10`,
+		`LIMIT -1`:               "expected a positive number after LIMIT at position 7",
+		`city = Pune Mumbai`:     `unexpected "Mumbai" at position 13`,
+		`city = Pune; DROP`:      `unexpected character ';' at position 12`,
+	} {
+		if _, err := Parse(text); err == nil || err.Error() != want {
+			t.Errorf("%s: error %v, want %q", text, err, want)
+		}
+	}
+}
+
+// book is the contacts queries are run on in the tests
+var book = []models.Contact{
+	{ID: "1", FirstName: "Ada", LastName: "Lovelace", CreatedOn: time.Date(2022, 12, 10, 9, 0, 0, 0, time.UTC),
+		Phones:    []models.Phone{{Type: models.MobilePhone, Number: "+447700900000"}},
+		Addresses: []models.Address{{City: "London", Country: "GB"}}},
+	{ID: "2", FirstName: "Grace", LastName: "Hopper", CreatedOn: time.Date(2023, 1, 1, 18, 30, 0, 0, time.UTC),
+		Emails:    []models.Email{{Type: models.WorkEmail, Address: "grace@navy.example.com"}},
+		Addresses: []models.Address{{City: "Arlington", State: "Virginia"}, {City: "New York"}}},
+	{ID: "3", FirstName: "Alan", LastName: "Turing", CreatedOn: time.Date(2023, 6, 23, 0, 0, 0, 0, time.UTC),
+		Addresses: []models.Address{{City: "Wilmslow", Country: "GB"}}},
+}
+
+func TestRun(t *testing.T) {
+	for text, want := range map[string]string{
+		"":                                      "1 2 3",
+		"first_name = ADA":                      "1",
+		"city = 'new york'":                     "2",
+		"city != london":                        "2 3",
+		"country = gb AND NOT last_name ~ love": "3",
+		"email ~ navy OR phone = +447700900000": "1 2",
+		"phone_type = work":                     "",
+		"created_on = 2023-01-01":               "2",
+		"created_on >= 2023-01-01":              "2 3",
+		"created_on < 2023-01-01T18:30:00Z":     "1",
+		"ORDER BY last_name":                    "2 1 3",
+		"ORDER BY country DESC, first_name":     "1 3 2",
+		"ORDER BY created_on DESC LIMIT 2":      "3 2",
+		"ORDER BY created_on LIMIT 2 OFFSET 2":  "3",
+		"OFFSET 5":                              "",
+	} {
+		query, err := Parse(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		var ids []string
+		for _, contact := range query.Run(book) {
+			ids = append(ids, contact.ID)
+		}
+		if got := strings.Join(ids, " "); got != want {
+			t.Errorf("%s: got %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDatesCompareInUTC(t *testing.T) {
+	// Early on the 1st of January in India is still the 31st of December in UTC
+	india := time.FixedZone("IST", 5*60*60+30*60)
+	contact := models.Contact{ID: "1", CreatedOn: time.Date(2023, 1, 1, 2, 0, 0, 0, india)}
+	for text, want := range map[string]bool{
+		"created_on = 2022-12-31":           true,
+		"created_on = 2023-01-01":           false,
+		"created_on < 2023-01-01":           true,
+		"created_on = 2022-12-31T20:30:00Z": true,
+		"created_on ~ 2022-12-31":           true,
+	} {
+		query, err := Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := query.Match(contact); got != want {
+			t.Errorf("%s: matched %v, want %v", text, got, want)
+		}
+	}
+}
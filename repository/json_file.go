@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const backupSuffix = ".bak"
+
+// JsonFileRepository keeps the address book as a single JSON document on disk.
+// Every save is written to a temporary file, fsynced and atomically renamed over the previous
+// generation, which is kept next to it with a .bak suffix.
+type JsonFileRepository struct {
+	Path string
+}
+
+// NewJsonFileRepository returns a repository backed by the JSON file at path
+func NewJsonFileRepository(path string) *JsonFileRepository {
+	return &JsonFileRepository{Path: path}
+}
+
+// Load reads the address book from the JSON file, falling back to the backup when the file is unreadable
+func (r *JsonFileRepository) Load() (Snapshot, error) {
+	snapshot, err := readSnapshot(r.Path)
+	if err == nil {
+		return snapshot, nil
+	}
+	slog.Info("failed to load address book json file, trying backup", err)
+	snapshot, backupErr := readSnapshot(r.Path + backupSuffix)
+	if backupErr != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// Save atomically replaces the JSON file with the given state
+func (r *JsonFileRepository) Save(snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "    ")
+	if err != nil {
+		slog.Info("failed to marshal contacts details", err)
+		return err
+	}
+
+	dir := filepath.Dir(r.Path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.Path)+".tmp-*")
+	if err != nil {
+		slog.Info("Failed to create temporary address book file", err)
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		slog.Info("Error writing to file:", err)
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		slog.Info("Failed to sync temporary address book file", err)
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = r.backup(); err != nil {
+		slog.Info("Failed to back up previous address book file", err)
+		return err
+	}
+	if err = os.Rename(tmpPath, r.Path); err != nil {
+		slog.Info("Failed to replace address book file", err)
+		return err
+	}
+	return syncDir(dir)
+}
+
+// backup keeps the current generation of the JSON file as a .bak before it gets replaced
+func (r *JsonFileRepository) backup() error {
+	backupPath := r.Path + backupSuffix
+	if err := os.Remove(backupPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err := os.Link(r.Path, backupPath)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	// Hard links aren't supported everywhere, copy the file instead
+	return copyFile(r.Path, backupPath)
+}
+
+func readSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+func copyFile(source, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir flushes the directory entry so the rename survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Some platforms can't fsync directories, the rename is still atomic there
+	_ = d.Sync()
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"sync"
+)
+
+// MemoryRepository keeps the address book in memory only, mostly useful for tests
+type MemoryRepository struct {
+	snapshot Snapshot
+	mutex    sync.Mutex
+}
+
+// NewMemoryRepository returns an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// Load returns a copy of the last saved state
+func (r *MemoryRepository) Load() (Snapshot, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return copySnapshot(r.snapshot), nil
+}
+
+// Save keeps a copy of the given state
+func (r *MemoryRepository) Save(snapshot Snapshot) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.snapshot = copySnapshot(snapshot)
+	return nil
+}
+
+// copySnapshot deep copies the maps of the snapshot and the lists of its contacts so callers can't alias the stored state
+func copySnapshot(snapshot Snapshot) Snapshot {
+	copied := Snapshot{
+		Contacts:   make(map[string]models.Contact, len(snapshot.Contacts)),
+		NameIndex:  make(map[string][]string, len(snapshot.NameIndex)),
+		PhoneIndex: make(map[string]string, len(snapshot.PhoneIndex)),
+	}
+	for key, contact := range snapshot.Contacts {
+		copied.Contacts[key] = copyContact(contact)
+	}
+	for key, keys := range snapshot.NameIndex {
+		copied.NameIndex[key] = append([]string(nil), keys...)
+	}
+	for key, value := range snapshot.PhoneIndex {
+		copied.PhoneIndex[key] = value
+	}
+	return copied
+}
+
+// copyContact copies the phones, emails and addresses of the contact, which would otherwise share their backing arrays
+func copyContact(contact models.Contact) models.Contact {
+	if contact.Phones != nil {
+		contact.Phones = append([]models.Phone(nil), contact.Phones...)
+	}
+	if contact.Emails != nil {
+		contact.Emails = append([]models.Email(nil), contact.Emails...)
+	}
+	if contact.Addresses != nil {
+		contact.Addresses = append([]models.Address(nil), contact.Addresses...)
+	}
+	return contact
+}
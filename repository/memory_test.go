@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"testing"
+)
+
+func TestMemoryRepositoryDoesNotShareContactLists(t *testing.T) {
+	repo := NewMemoryRepository()
+	saved := Snapshot{Contacts: map[string]models.Contact{"a": {ID: "a", FirstName: "Ada",
+		Phones:    []models.Phone{{Type: "mobile", Number: "+14155550100"}},
+		Emails:    []models.Email{{Type: "work", Address: "ada@example.com"}},
+		Addresses: []models.Address{{City: "London"}},
+	}}}
+	if err := repo.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	saved.Contacts["a"].Phones[0].Number = "+14155550199"
+
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Contacts["a"].Emails[0].Address = "changed@example.com"
+	loaded.Contacts["a"].Addresses[0].City = "Paris"
+
+	stored, _ := repo.Load()
+	contact := stored.Contacts["a"]
+	if contact.Phones[0].Number != "+14155550100" || contact.Emails[0].Address != "ada@example.com" || contact.Addresses[0].City != "London" {
+		t.Errorf("stored contact changed through a caller's copy: %+v", contact)
+	}
+}
@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"database/sql"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"io/fs"
+	"time"
+)
+
+// migration upgrades the SQLite schema or data to the given version.
+// Data migrations write through the current schema, so they run once every schema migration is applied.
+type migration struct {
+	version     int
+	description string
+	data        bool
+	up          func(tx *sql.Tx, r *SqliteRepository) error
+}
+
+// migrations lists every schema version in order, never edit a migration once released
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create contacts and addresses tables",
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			statements := []string{
+				`CREATE TABLE contacts (
+					key           TEXT PRIMARY KEY,
+					first_name    TEXT NOT NULL,
+					last_name     TEXT NOT NULL,
+					name_key      TEXT NOT NULL,
+					email_address TEXT NOT NULL,
+					phone_number  TEXT NOT NULL,
+					created_on    TEXT NOT NULL
+				)`,
+				`CREATE INDEX idx_contacts_name_key ON contacts (name_key)`,
+				`CREATE INDEX idx_contacts_phone_number ON contacts (phone_number)`,
+				`CREATE TABLE addresses (
+					id          INTEGER PRIMARY KEY AUTOINCREMENT,
+					contact_key TEXT NOT NULL REFERENCES contacts (key) ON DELETE CASCADE,
+					type        TEXT NOT NULL,
+					street      TEXT NOT NULL,
+					city        TEXT NOT NULL,
+					state       TEXT NOT NULL,
+					zip         TEXT NOT NULL,
+					country     TEXT NOT NULL
+				)`,
+				`CREATE INDEX idx_addresses_contact_key ON addresses (contact_key)`,
+			}
+			for _, statement := range statements {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     2,
+		description: "import the existing JSON address book",
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			if r.ImportPath == "" {
+				return nil
+			}
+			snapshot, err := readSnapshot(r.ImportPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			for key, contact := range snapshot.Contacts {
+				if err = insertContact(tx, key, contact); err != nil {
+					return err
+				}
+			}
+			slog.Info("imported json address book into sqlite", "contacts", len(snapshot.Contacts))
+			return nil
+		},
+	},
+	{
+		version:     3,
+		description: "move phone numbers and emails to typed lists",
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			statements := []string{
+				`CREATE TABLE phones (
+					id          INTEGER PRIMARY KEY AUTOINCREMENT,
+					contact_key TEXT NOT NULL REFERENCES contacts (key) ON DELETE CASCADE,
+					type        TEXT NOT NULL,
+					number      TEXT NOT NULL,
+					is_primary  INTEGER NOT NULL DEFAULT 0
+				)`,
+				`CREATE INDEX idx_phones_number ON phones (number)`,
+				`CREATE INDEX idx_phones_contact_key ON phones (contact_key)`,
+				`CREATE TABLE emails (
+					id          INTEGER PRIMARY KEY AUTOINCREMENT,
+					contact_key TEXT NOT NULL REFERENCES contacts (key) ON DELETE CASCADE,
+					type        TEXT NOT NULL,
+					address     TEXT NOT NULL,
+					is_primary  INTEGER NOT NULL DEFAULT 0
+				)`,
+				`CREATE INDEX idx_emails_contact_key ON emails (contact_key)`,
+				`INSERT INTO phones (contact_key, type, number, is_primary)
+					SELECT key, 'mobile', phone_number, 1 FROM contacts WHERE phone_number <> ''`,
+				`INSERT INTO emails (contact_key, type, address, is_primary)
+					SELECT key, 'personal', email_address, 1 FROM contacts WHERE email_address <> ''`,
+				`ALTER TABLE addresses ADD COLUMN is_primary INTEGER NOT NULL DEFAULT 0`,
+				`UPDATE addresses SET type = lower(type), is_primary = 1`,
+				`DROP INDEX idx_contacts_phone_number`,
+				`ALTER TABLE contacts DROP COLUMN phone_number`,
+				`ALTER TABLE contacts DROP COLUMN email_address`,
+			}
+			for _, statement := range statements {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     4,
+		description: "import the phones, emails, addresses and types the first schema couldn't hold",
+		data:        true,
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			if r.ImportPath == "" {
+				return nil
+			}
+			snapshot, err := readSnapshot(r.ImportPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			completed := 0
+			for key, contact := range snapshot.Contacts {
+				added, err := completeImport(tx, key, contact)
+				if err != nil {
+					return err
+				}
+				if added {
+					completed++
+				}
+			}
+			if completed > 0 {
+				slog.Info("completed the json address book import", "contacts", completed)
+			}
+			return nil
+		},
+	},
+	{
+		version:     5,
+		description: "give the contacts imported under legacy first-last-phone keys generated IDs",
+		data:        true,
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			// Legacy keys were built as first name, last name and phone number joined by dashes
+			keys, err := queryStrings(tx, `SELECT key FROM contacts
+				WHERE substr(key, 1, length(first_name) + length(last_name) + 2) = first_name || '-' || last_name || '-'`)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err = rekeyContact(tx, key, utility.NewContactID()); err != nil {
+					return err
+				}
+			}
+			if len(keys) > 0 {
+				slog.Info("moved sqlite contacts to generated IDs", "contacts", len(keys))
+			}
+			return nil
+		},
+	},
+	{
+		version:     6,
+		description: "add the vCard UID and last modification time of contacts",
+		up: func(tx *sql.Tx, r *SqliteRepository) error {
+			statements := []string{
+				`ALTER TABLE contacts ADD COLUMN uid TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE contacts ADD COLUMN modified_on TEXT NOT NULL DEFAULT ''`,
+			}
+			for _, statement := range statements {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// insertContact writes a contact in the first schema, a single phone number, email and address in columns
+// of the contacts and addresses tables, as migration 2 imports the JSON book before the later migrations run.
+// It belongs to the released migrations, never change it.
+func insertContact(tx *sql.Tx, key string, contact models.Contact) error {
+	_, err := tx.Exec(`INSERT INTO contacts (key, first_name, last_name, name_key, email_address, phone_number, created_on)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key, contact.FirstName, contact.LastName, nameKey(contact), contact.PrimaryEmail().Address,
+		contact.PrimaryPhone().Number, contact.CreatedOn.Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+
+	address := contact.PrimaryAddress()
+	if address == (models.Address{}) {
+		return nil
+	}
+	_, err = tx.Exec(`INSERT INTO addresses (contact_key, type, street, city, state, zip, country)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key, address.Type, address.Street, address.City, address.State, address.Zip, address.Country)
+	return err
+}
+
+// completeImport adds the phones, emails and addresses the first schema dropped from an imported contact, and the type
+// of its primary phone and email, as long as the stored contact still only has the primary ones.
+// It tells whether anything was changed.
+func completeImport(tx *sql.Tx, key string, contact models.Contact) (bool, error) {
+	added := false
+
+	primaryPhone := contact.PrimaryPhone()
+	numbers, err := queryStrings(tx, `SELECT number FROM phones WHERE contact_key = ? ORDER BY id`, key)
+	if err != nil {
+		return false, err
+	}
+	if len(numbers) == 1 && numbers[0] == primaryPhone.Number {
+		// Migration 3 typed every imported phone as mobile
+		result, err := tx.Exec(`UPDATE phones SET type = ? WHERE contact_key = ? AND type = 'mobile' AND type <> ?`,
+			primaryPhone.Type, key, primaryPhone.Type)
+		if err != nil {
+			return false, err
+		}
+		if changed, _ := result.RowsAffected(); changed > 0 {
+			added = true
+		}
+	}
+	if len(contact.Phones) > 1 && len(numbers) == 1 && numbers[0] == primaryPhone.Number {
+		for _, phone := range contact.Phones {
+			if phone == primaryPhone {
+				continue
+			}
+			_, err = tx.Exec(`INSERT INTO phones (contact_key, type, number, is_primary) VALUES (?, ?, ?, 0)`,
+				key, phone.Type, phone.Number)
+			if err != nil {
+				return false, err
+			}
+		}
+		added = true
+	}
+
+	primaryEmail := contact.PrimaryEmail()
+	addresses, err := queryStrings(tx, `SELECT address FROM emails WHERE contact_key = ? ORDER BY id`, key)
+	if err != nil {
+		return false, err
+	}
+	if len(addresses) == 1 && addresses[0] == primaryEmail.Address {
+		// Migration 3 typed every imported email as personal
+		result, err := tx.Exec(`UPDATE emails SET type = ? WHERE contact_key = ? AND type = 'personal' AND type <> ?`,
+			primaryEmail.Type, key, primaryEmail.Type)
+		if err != nil {
+			return false, err
+		}
+		if changed, _ := result.RowsAffected(); changed > 0 {
+			added = true
+		}
+	}
+	if len(contact.Emails) > 1 && len(addresses) == 1 && addresses[0] == primaryEmail.Address {
+		for _, email := range contact.Emails {
+			if email == primaryEmail {
+				continue
+			}
+			_, err = tx.Exec(`INSERT INTO emails (contact_key, type, address, is_primary) VALUES (?, ?, ?, 0)`,
+				key, email.Type, email.Address)
+			if err != nil {
+				return false, err
+			}
+		}
+		added = true
+	}
+
+	primaryAddress := contact.PrimaryAddress()
+	zips, err := queryStrings(tx, `SELECT zip FROM addresses WHERE contact_key = ? ORDER BY id`, key)
+	if err != nil {
+		return false, err
+	}
+	if len(contact.Addresses) > 1 && len(zips) == 1 && zips[0] == primaryAddress.Zip {
+		for _, address := range contact.Addresses {
+			if address == primaryAddress {
+				continue
+			}
+			_, err = tx.Exec(`INSERT INTO addresses (contact_key, type, street, city, state, zip, country, is_primary)
+				VALUES (?, ?, ?, ?, ?, ?, ?, 0)`,
+				key, address.Type, address.Street, address.City, address.State, address.Zip, address.Country)
+			if err != nil {
+				return false, err
+			}
+		}
+		added = true
+	}
+	return added, nil
+}
+
+// rekeyContact moves a contact and its phones, emails and addresses to a new key
+func rekeyContact(tx *sql.Tx, key, newKey string) error {
+	_, err := tx.Exec(`INSERT INTO contacts (key, first_name, last_name, name_key, created_on)
+		SELECT ?, first_name, last_name, name_key, created_on FROM contacts WHERE key = ?`, newKey, key)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"phones", "emails", "addresses"} {
+		if _, err = tx.Exec(`UPDATE `+table+` SET contact_key = ? WHERE contact_key = ?`, newKey, key); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`DELETE FROM contacts WHERE key = ?`, key)
+	return err
+}
+
+// queryStrings returns the single text column of every row of the query
+func queryStrings(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// migrate applies, in order and each in its own transaction, every migration not yet recorded in the database
+func (r *SqliteRepository) migrate() error {
+	_, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_on TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool)
+	rows, err := r.db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version int
+		if err = rows.Scan(&version); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	if err = rows.Close(); err != nil {
+		return err
+	}
+
+	// Schema migrations first, then data migrations which rely on the latest schema
+	for _, data := range []bool{false, true} {
+		for _, m := range migrations {
+			if m.data != data || applied[m.version] {
+				continue
+			}
+			if err = r.apply(m); err != nil {
+				slog.Info("failed to apply sqlite migration", "version", m.version, "description", m.description, "err", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apply runs the migration and records its version in a single transaction
+func (r *SqliteRepository) apply(m migration) error {
+	return r.inTransaction(func(tx *sql.Tx) error {
+		if err := m.up(tx, r); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_on) VALUES (?, ?)`,
+			m.version, time.Now().Format(time.RFC3339Nano))
+		return err
+	})
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"time"
+)
+
+const (
+	AddOperation    = "add"
+	UpdateOperation = "update"
+	DeleteOperation = "delete"
+)
+
+// Operation describes a single change applied to the address book
+type Operation struct {
+	Type    string         `json:"type"`
+	Key     string         `json:"key"` // ID of the contact the change applies to
+	Contact models.Contact `json:"contact"`
+	Time    time.Time      `json:"time"`
+}
+
+// Journal is implemented by repositories able to persist changes without rewriting the whole book
+type Journal interface {
+	// Apply persists the operations as a whole: either all of them are recorded or none is
+	Apply(operations ...Operation) error
+}
+
+// Finder is implemented by repositories maintaining their own name and phone number indexes
+type Finder interface {
+	// FindByName returns the keys of the contacts indexed under the name key
+	FindByName(nameKey string) ([]string, error)
+	// FindByPhoneNumber returns the key of the contact owning the phone number
+	FindByPhoneNumber(phoneNumber string) (string, bool, error)
+}
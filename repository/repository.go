@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"errors"
+	"github.com/spf13/viper"
+)
+
+var (
+	UnknownStorageType = errors.New("unknown storage type configured")
+)
+
+// Snapshot is the persisted state of an address book
+type Snapshot struct {
+	Contacts   map[string]models.Contact `json:"contacts,omitempty"`    // Contacts by key
+	NameIndex  map[string][]string       `json:"name_index,omitempty"`  // Index for name search
+	PhoneIndex map[string]string         `json:"phone_index,omitempty"` // Index for phone search, covering every number of a contact
+}
+
+// Repository stores and restores the address book state
+type Repository interface {
+	// Load returns the last saved state of the address book
+	Load() (Snapshot, error)
+	// Save persists the whole state of the address book
+	Save(snapshot Snapshot) error
+}
+
+// NewRepository returns the repository selected by the storage configurations
+func NewRepository() (Repository, error) {
+	storageType := viper.GetString(constants.StorageType)
+	switch storageType {
+	case "", constants.JsonStorage:
+		path := viper.GetString(constants.StoragePath)
+		if path == "" {
+			path = constants.AddressBookFilePath
+		}
+		return NewJsonFileRepository(path), nil
+	case constants.MemoryStorage:
+		return NewMemoryRepository(), nil
+	case constants.SqliteStorage:
+		path := viper.GetString(constants.StoragePath)
+		if path == "" {
+			path = constants.SqliteFilePath
+		}
+		importPath := viper.GetString(constants.StorageImportPath)
+		if importPath == "" {
+			importPath = constants.AddressBookFilePath
+		}
+		return NewSqliteRepository(path, importPath)
+	case constants.WalStorage:
+		path := viper.GetString(constants.StoragePath)
+		if path == "" {
+			path = constants.AddressBookFilePath
+		}
+		maxSize := viper.GetInt64(constants.StorageWalMaxSize)
+		if maxSize <= 0 {
+			maxSize = constants.DefaultWalMaxSize
+		}
+		return NewWalRepository(NewJsonFileRepository(path), path+constants.WalFileSuffix, maxSize)
+	default:
+		return nil, UnknownStorageType
+	}
+}
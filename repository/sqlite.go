@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/sagikazarmark/slog-shim"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SqliteRepository stores contacts with their phones, emails and addresses in an embedded SQLite database.
+// Name and phone number lookups are answered by indexed columns, and each change only touches
+// the rows of the contact it concerns.
+type SqliteRepository struct {
+	Path       string
+	ImportPath string // JSON address book imported on first start, if it exists
+	db         *sql.DB
+}
+
+// NewSqliteRepository opens the database at path and migrates its schema to the latest version
+func NewSqliteRepository(path, importPath string) (*SqliteRepository, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports a single writer, serialize everything through one connection
+	db.SetMaxOpenConns(1)
+
+	repo := &SqliteRepository{Path: path, ImportPath: importPath, db: db}
+	if err = repo.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// Close releases the database handle
+func (r *SqliteRepository) Close() error {
+	return r.db.Close()
+}
+
+// Load reads every contact from the database. The name and phone indexes are left empty,
+// lookups being answered by the indexed columns through FindByName and FindByPhoneNumber.
+func (r *SqliteRepository) Load() (Snapshot, error) {
+	snapshot := Snapshot{Contacts: make(map[string]models.Contact)}
+
+	rows, err := r.db.Query(`SELECT key, uid, first_name, last_name, created_on, modified_on FROM contacts ORDER BY name_key, key`)
+	if err != nil {
+		slog.Info("failed to query contacts", err)
+		return snapshot, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, createdOn, modifiedOn string
+		var contact models.Contact
+		if err = rows.Scan(&key, &contact.UID, &contact.FirstName, &contact.LastName, &createdOn, &modifiedOn); err != nil {
+			return snapshot, err
+		}
+		if contact.CreatedOn, err = parseTime(createdOn); err != nil {
+			return snapshot, err
+		}
+		if contact.ModifiedOn, err = parseTime(modifiedOn); err != nil {
+			return snapshot, err
+		}
+		contact.ID = key
+		snapshot.Contacts[key] = contact
+	}
+	if err = rows.Err(); err != nil {
+		return snapshot, err
+	}
+
+	err = r.scanRows(`SELECT contact_key, type, number, is_primary FROM phones ORDER BY id`, func(scan func(dest ...interface{}) error) error {
+		var key string
+		var phone models.Phone
+		if err := scan(&key, &phone.Type, &phone.Number, &phone.Primary); err != nil {
+			return err
+		}
+		if contact, found := snapshot.Contacts[key]; found {
+			contact.Phones = append(contact.Phones, phone)
+			snapshot.Contacts[key] = contact
+		}
+		return nil
+	})
+	if err != nil {
+		return snapshot, err
+	}
+
+	err = r.scanRows(`SELECT contact_key, type, address, is_primary FROM emails ORDER BY id`, func(scan func(dest ...interface{}) error) error {
+		var key string
+		var email models.Email
+		if err := scan(&key, &email.Type, &email.Address, &email.Primary); err != nil {
+			return err
+		}
+		if contact, found := snapshot.Contacts[key]; found {
+			contact.Emails = append(contact.Emails, email)
+			snapshot.Contacts[key] = contact
+		}
+		return nil
+	})
+	if err != nil {
+		return snapshot, err
+	}
+
+	err = r.scanRows(`SELECT contact_key, type, street, city, state, zip, country, is_primary FROM addresses ORDER BY id`, func(scan func(dest ...interface{}) error) error {
+		var key string
+		var address models.Address
+		if err := scan(&key, &address.Type, &address.Street, &address.City, &address.State, &address.Zip, &address.Country, &address.Primary); err != nil {
+			return err
+		}
+		if contact, found := snapshot.Contacts[key]; found {
+			contact.Addresses = append(contact.Addresses, address)
+			snapshot.Contacts[key] = contact
+		}
+		return nil
+	})
+	return snapshot, err
+}
+
+// scanRows runs a query and hands every row to read
+func (r *SqliteRepository) scanRows(query string, read func(scan func(dest ...interface{}) error) error) error {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		slog.Info("failed to query contact details", err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = read(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Save replaces the whole content of the database with the given state
+func (r *SqliteRepository) Save(snapshot Snapshot) error {
+	return r.inTransaction(func(tx *sql.Tx) error {
+		for _, table := range []string{"phones", "emails", "addresses", "contacts"} {
+			if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+				return err
+			}
+		}
+		for key, contact := range snapshot.Contacts {
+			if err := writeContact(tx, key, contact); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Apply persists the changes to the book in a single transaction
+func (r *SqliteRepository) Apply(operations ...Operation) error {
+	return r.inTransaction(func(tx *sql.Tx) error {
+		for _, operation := range operations {
+			if err := applyOperation(tx, operation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func applyOperation(tx *sql.Tx, operation Operation) error {
+	switch operation.Type {
+	case AddOperation, UpdateOperation:
+		if err := deleteContact(tx, operation.Key); err != nil {
+			return err
+		}
+		return writeContact(tx, operation.Key, operation.Contact)
+	case DeleteOperation:
+		return deleteContact(tx, operation.Key)
+	default:
+		return fmt.Errorf("unknown operation type %q", operation.Type)
+	}
+}
+
+// FindByName returns the keys of the contacts whose name key matches
+func (r *SqliteRepository) FindByName(nameKey string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT key FROM contacts WHERE name_key = ? ORDER BY key`, nameKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// FindByPhoneNumber returns the key of the contact owning the phone number
+func (r *SqliteRepository) FindByPhoneNumber(phoneNumber string) (string, bool, error) {
+	var key string
+	err := r.db.QueryRow(`SELECT contact_key FROM phones WHERE number = ? ORDER BY id LIMIT 1`, phoneNumber).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return key, true, nil
+}
+
+func (r *SqliteRepository) inTransaction(apply func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = apply(tx); err != nil {
+		_ = tx.Rollback()
+		slog.Info("failed to write address book database", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeContact inserts the contact with its phones, emails and addresses in the latest schema
+func writeContact(tx *sql.Tx, key string, contact models.Contact) error {
+	_, err := tx.Exec(`INSERT INTO contacts (key, uid, first_name, last_name, name_key, created_on, modified_on) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key, contact.UID, contact.FirstName, contact.LastName, nameKey(contact),
+		contact.CreatedOn.Format(time.RFC3339Nano), formatTime(contact.ModifiedOn))
+	if err != nil {
+		return err
+	}
+
+	for _, phone := range contact.Phones {
+		_, err = tx.Exec(`INSERT INTO phones (contact_key, type, number, is_primary) VALUES (?, ?, ?, ?)`,
+			key, phone.Type, phone.Number, phone.Primary)
+		if err != nil {
+			return err
+		}
+	}
+	for _, email := range contact.Emails {
+		_, err = tx.Exec(`INSERT INTO emails (contact_key, type, address, is_primary) VALUES (?, ?, ?, ?)`,
+			key, email.Type, email.Address, email.Primary)
+		if err != nil {
+			return err
+		}
+	}
+	for _, address := range contact.Addresses {
+		_, err = tx.Exec(`INSERT INTO addresses (contact_key, type, street, city, state, zip, country, is_primary)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			key, address.Type, address.Street, address.City, address.State, address.Zip, address.Country, address.Primary)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteContact(tx *sql.Tx, key string) error {
+	for _, table := range []string{"phones", "emails", "addresses"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE contact_key = ?`, key); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`DELETE FROM contacts WHERE key = ?`, key)
+	return err
+}
+
+// nameKey mirrors the name key the address book uses for its name index
+func nameKey(contact models.Contact) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(contact.FirstName), strings.ToLower(contact.LastName))
+}
+
+// formatTime writes a time, leaving the unknown ones empty
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(time.RFC3339Nano)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSqliteApplyIsAtomic(t *testing.T) {
+	repo, err := NewSqliteRepository(filepath.Join(t.TempDir(), "book.db"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	contact := models.Contact{ID: "a", FirstName: "Ada", LastName: "Lovelace",
+		Phones: []models.Phone{{Type: "mobile", Number: "+14155550100", Primary: true}}}
+	if err = repo.Apply(Operation{Type: AddOperation, Key: "a", Contact: contact}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.Apply(
+		Operation{Type: DeleteOperation, Key: "a"},
+		Operation{Type: "rename", Key: "a"},
+	)
+	if err == nil {
+		t.Fatal("Apply of an unknown operation succeeded")
+	}
+
+	key, found, err := repo.FindByPhoneNumber("+14155550100")
+	if err != nil || !found || key != "a" {
+		t.Errorf("FindByPhoneNumber = %q, %v, %v, want the contact kept by the rolled back batch", key, found, err)
+	}
+	keys, err := repo.FindByName("ada-lovelace")
+	if err != nil || len(keys) != 1 {
+		t.Errorf("FindByName = %v, %v, want [a]", keys, err)
+	}
+}
+
+func TestSqliteKeepsUIDAndModificationTime(t *testing.T) {
+	repo, err := NewSqliteRepository(filepath.Join(t.TempDir(), "book.db"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	contacts := map[string]models.Contact{
+		"a": {ID: "a", UID: "ada@example.com", FirstName: "Ada", LastName: "Lovelace",
+			CreatedOn:  time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			ModifiedOn: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)},
+		"b": {ID: "b", FirstName: "Alan", LastName: "Turing", CreatedOn: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	if err = repo.Save(Snapshot{Contacts: contacts}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := repo.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot.Contacts, contacts) {
+		t.Errorf("loaded %+v, want %+v", snapshot.Contacts, contacts)
+	}
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Replayer is implemented by repositories holding changes not yet folded into the loaded snapshot
+type Replayer interface {
+	// Replay calls apply with every recorded change, oldest first
+	Replay(apply func(operation Operation) error) error
+}
+
+// Compactor is implemented by repositories whose change log should periodically be folded into a snapshot
+type Compactor interface {
+	NeedsCompaction() bool
+}
+
+// WalRepository records every change as a JSON line in a write-ahead log next to a snapshot.
+// Saving a snapshot truncates the log, so replaying operations on a snapshot which already contains
+// them after a crash is harmless: every operation stores or removes a contact under a known key.
+type WalRepository struct {
+	Snapshots Repository
+	Path      string
+	MaxSize   int64 // Size in bytes past which the log should be compacted into a new snapshot
+	file      *os.File
+	size      int64
+	mutex     sync.Mutex
+}
+
+// NewWalRepository returns a repository logging changes at path on top of the snapshot repository
+func NewWalRepository(snapshots Repository, path string, maxSize int64) (*WalRepository, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &WalRepository{Snapshots: snapshots, Path: path, MaxSize: maxSize, file: file, size: info.Size()}, nil
+}
+
+// Close releases the log file
+func (r *WalRepository) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.file.Close()
+}
+
+// Load returns the last snapshot, the logged changes are applied on top of it through Replay
+func (r *WalRepository) Load() (Snapshot, error) {
+	snapshot, err := r.Snapshots.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		// Nothing was compacted yet, the whole book lives in the log
+		return Snapshot{}, nil
+	}
+	return snapshot, err
+}
+
+// Save writes a new snapshot and empties the log
+func (r *WalRepository) Save(snapshot Snapshot) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.Snapshots.Save(snapshot); err != nil {
+		return err
+	}
+	if err := r.file.Truncate(0); err != nil {
+		slog.Info("failed to truncate write-ahead log", err)
+		return err
+	}
+	r.size = 0
+	return r.file.Sync()
+}
+
+// Apply appends the changes to the log and flushes it to disk. Several changes are written as a JSON array
+// on a single line, so that a torn write drops all of them on replay.
+func (r *WalRepository) Apply(operations ...Operation) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var data []byte
+	var err error
+	if len(operations) == 1 {
+		data, err = json.Marshal(operations[0])
+	} else {
+		data, err = json.Marshal(operations)
+	}
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err = r.file.Write(data); err != nil {
+		slog.Info("failed to append to write-ahead log", err)
+		r.discardAppend()
+		return err
+	}
+	if err = r.file.Sync(); err != nil {
+		slog.Info("failed to sync write-ahead log", err)
+		r.discardAppend()
+		return err
+	}
+	r.size += int64(len(data))
+	return nil
+}
+
+// Replay reads the log from the start and applies each recorded change.
+// A torn last line, left by a crash in the middle of an append, is dropped from the log.
+func (r *WalRepository) Replay(apply func(operation Operation) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(r.file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				slog.Info("dropping incomplete write-ahead log entry", "offset", offset)
+				return r.truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		operations, err := decodeEntry(line)
+		if err != nil {
+			slog.Info("failed to decode write-ahead log entry", "offset", offset, "err", err)
+			return err
+		}
+		for _, operation := range operations {
+			if err = apply(operation); err != nil {
+				return err
+			}
+		}
+		offset += int64(len(line))
+	}
+}
+
+// decodeEntry reads a line of the log, holding either a single operation or an array of operations applied together
+func decodeEntry(line []byte) ([]Operation, error) {
+	if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 && trimmed[0] == '[' {
+		var operations []Operation
+		err := json.Unmarshal(trimmed, &operations)
+		return operations, err
+	}
+	var operation Operation
+	if err := json.Unmarshal(line, &operation); err != nil {
+		return nil, err
+	}
+	return []Operation{operation}, nil
+}
+
+// NeedsCompaction tells whether the log grew past its maximum size
+func (r *WalRepository) NeedsCompaction() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.MaxSize > 0 && r.size > r.MaxSize
+}
+
+// discardAppend cuts what a failed append left after the last complete line, so the next one doesn't follow a torn line
+func (r *WalRepository) discardAppend() {
+	if err := r.file.Truncate(r.size); err != nil {
+		slog.Info("failed to discard incomplete write-ahead log entry", err)
+		return
+	}
+	if _, err := r.file.Seek(r.size, io.SeekStart); err != nil {
+		slog.Info("failed to discard incomplete write-ahead log entry", err)
+	}
+}
+
+func (r *WalRepository) truncate(size int64) error {
+	if err := r.file.Truncate(size); err != nil {
+		return err
+	}
+	r.size = size
+	return r.file.Sync()
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"GoAddressBook/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalReplaysBatchesWhole(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.wal")
+	wal, err := NewWalRepository(NewMemoryRepository(), path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := Operation{Type: AddOperation, Key: "a", Contact: models.Contact{ID: "a", FirstName: "Ada"}}
+	update := Operation{Type: UpdateOperation, Key: "b", Contact: models.Contact{ID: "b", FirstName: "Alan"}}
+	remove := Operation{Type: DeleteOperation, Key: "a"}
+	if err = wal.Apply(add); err != nil {
+		t.Fatal(err)
+	}
+	if err = wal.Apply(update, remove); err != nil {
+		t.Fatal(err)
+	}
+	_ = wal.Close()
+
+	tests := []struct {
+		name string
+		torn int // bytes cut from the end of the log
+		want []string
+	}{
+		{name: "complete log", want: []string{"add a", "update b", "delete a"}},
+		{name: "torn batch", torn: 10, want: []string{"add a"}},
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			torn := filepath.Join(t.TempDir(), "book.wal")
+			if err := os.WriteFile(torn, data[:len(data)-test.torn], 0o644); err != nil {
+				t.Fatal(err)
+			}
+			wal, err := NewWalRepository(NewMemoryRepository(), torn, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer wal.Close()
+			var got []string
+			err = wal.Replay(func(operation Operation) error {
+				got = append(got, operation.Type+" "+operation.Key)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("replayed %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("operation %d = %q, want %q", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWalDiscardsFailedAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.wal")
+	wal, err := NewWalRepository(NewMemoryRepository(), path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wal.Apply(Operation{Type: AddOperation, Key: "a", Contact: models.Contact{ID: "a", FirstName: "Ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	// What a write failing halfway leaves behind
+	if _, err = wal.file.WriteString(`{"type":"add","key":"b","con`); err != nil {
+		t.Fatal(err)
+	}
+	wal.discardAppend()
+	if err = wal.Apply(Operation{Type: DeleteOperation, Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = wal.Close()
+
+	wal, err = NewWalRepository(NewMemoryRepository(), path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	var got []string
+	err = wal.Replay(func(operation Operation) error {
+		got = append(got, operation.Type+" "+operation.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "add a" || got[1] != "delete a" {
+		t.Errorf("replayed %v, want [add a delete a]", got)
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"encoding/json"
+	"github.com/spf13/viper"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const byPhonePrefix = "by-phone/"
+
+// ContactsBody is the body of the responses listing contacts
+type ContactsBody struct {
+	Contacts   []models.Contact `json:"contacts"`
+	NextCursor string           `json:"next_cursor,omitempty"` // cursor of the next page of a listing, empty on the last one
+}
+
+// handleContacts serves the collection of contacts
+func (s *Server) handleContacts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listContacts(w, r)
+	case http.MethodPost:
+		s.createContact(w, r)
+	default:
+		methodNotAllowed(w, "GET, POST")
+	}
+}
+
+// handleContact serves a contact, by ID or by phone number
+func (s *Server) handleContact(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/contacts/")
+	if strings.HasPrefix(rest, byPhonePrefix) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, "GET")
+			return
+		}
+		s.getContactByPhone(w, strings.TrimPrefix(rest, byPhonePrefix))
+		return
+	}
+	if rest == "" || strings.Contains(rest, "/") {
+		writeError(w, http.StatusNotFound, NotFoundCode, "no such resource")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getContact(w, rest)
+	case http.MethodPut:
+		s.updateContact(w, r, rest)
+	case http.MethodDelete:
+		s.deleteContact(w, rest)
+	default:
+		methodNotAllowed(w, "GET, PUT, DELETE")
+	}
+}
+
+// listContacts answers the contacts matching the search parameters, or a page of all of them
+func (s *Server) listContacts(w http.ResponseWriter, r *http.Request) {
+	parameters := r.URL.Query()
+	limit := 0
+	if text := parameters.Get("limit"); text != "" {
+		var err error
+		if limit, err = strconv.Atoi(text); err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, InvalidRequestCode, "limit must be a positive number")
+			return
+		}
+	}
+
+	var body ContactsBody
+	switch {
+	case parameters.Has("name"):
+		if limit == 0 {
+			limit = viper.GetInt(constants.SearchLimit)
+		}
+		matches := s.Book.SearchNames(parameters.Get("name"), addressbook.NameSearchOptions{
+			Prefix:      true,
+			MaxDistance: viper.GetInt(constants.SearchMaxDistance),
+			Limit:       limit,
+			Phonetic:    viper.GetBool(constants.SearchPhonetic),
+		})
+		for _, match := range matches {
+			body.Contacts = append(body.Contacts, match.Contact)
+		}
+	case parameters.Has("q"):
+		contacts, err := s.Book.Search(parameters.Get("q"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, InvalidRequestCode, err.Error())
+			return
+		}
+		body.Contacts = contacts
+	case parameters.Has("query"):
+		contacts, err := s.Book.Query(parameters.Get("query"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, InvalidRequestCode, err.Error())
+			return
+		}
+		body.Contacts = contacts
+	default:
+		descending, _ := strconv.ParseBool(parameters.Get("desc"))
+		page, err := s.Book.List(addressbook.ListOptions{
+			SortBy:     parameters.Get("sort"),
+			Descending: descending,
+			Limit:      limit,
+			Cursor:     parameters.Get("cursor"),
+		})
+		if err != nil {
+			writeBookError(w, err)
+			return
+		}
+		body = ContactsBody{Contacts: page.Contacts, NextCursor: page.NextCursor}
+	}
+	if body.Contacts == nil {
+		body.Contacts = []models.Contact{}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// createContact validates and adds the contact of the body, answering it with its new ID
+func (s *Server) createContact(w http.ResponseWriter, r *http.Request) {
+	contact, ok := decodeContact(w, r)
+	if !ok {
+		return
+	}
+	// The ID is always generated by the book
+	contact.ID = ""
+	if contact.CreatedOn.IsZero() {
+		contact.CreatedOn = time.Now()
+	}
+	contact.NormalizePrimary()
+	if err := utility.ValidateContact(s.Validator, contact); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	id, err := s.Book.AddContact(contact)
+	if err != nil {
+		writeBookError(w, err)
+		return
+	}
+	created, _ := s.Book.GetContact(id)
+	w.Header().Set("Location", "/contacts/"+id)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// getContact answers the contact with the ID
+func (s *Server) getContact(w http.ResponseWriter, id string) {
+	contact, found := s.Book.GetContact(id)
+	if !found {
+		writeBookError(w, addressbook.ContactNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, contact)
+}
+
+// getContactByPhone answers the contact with the phone number, written in any format
+func (s *Server) getContactByPhone(w http.ResponseWriter, phone string) {
+	contact, found := s.Book.SearchByPhoneNumber(phone)
+	if !found {
+		writeBookError(w, addressbook.ContactNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, contact)
+}
+
+// updateContact validates the contact of the body and replaces the one with the ID by it
+func (s *Server) updateContact(w http.ResponseWriter, r *http.Request, id string) {
+	if _, found := s.Book.GetContact(id); !found {
+		writeBookError(w, addressbook.ContactNotFound)
+		return
+	}
+	contact, ok := decodeContact(w, r)
+	if !ok {
+		return
+	}
+	contact.ID = id
+	contact.NormalizePrimary()
+	if err := utility.ValidateContact(s.Validator, contact); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	if err := s.Book.UpdateContact(id, contact); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	updated, _ := s.Book.GetContact(id)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// deleteContact removes the contact with the ID
+func (s *Server) deleteContact(w http.ResponseWriter, id string) {
+	if _, err := s.Book.DeleteContact(id); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeContact reads the contact of the body, answering a bad request when it isn't valid JSON
+func decodeContact(w http.ResponseWriter, r *http.Request) (models.Contact, bool) {
+	var contact models.Contact
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err := decoder.Decode(&contact); err != nil {
+		writeError(w, http.StatusBadRequest, InvalidRequestCode, "invalid contact: "+err.Error())
+		return models.Contact{}, false
+	}
+	return contact, true
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/utility"
+	"encoding/json"
+	"errors"
+	"github.com/sagikazarmark/slog-shim"
+	"net/http"
+)
+
+// Codes of the errors answered by the API
+const (
+	InvalidRequestCode   = "invalid_request"
+	ValidationFailedCode = "validation_failed"
+	NotFoundCode         = "not_found"
+	ConflictCode         = "conflict"
+	MethodNotAllowedCode = "method_not_allowed"
+	InternalErrorCode    = "internal_error"
+)
+
+// ErrorBody is the body of the responses of failed requests
+type ErrorBody struct {
+	Error ErrorDetails `json:"error"`
+}
+
+// ErrorDetails tells why a request failed, listing the invalid fields when the contact failed validation
+type ErrorDetails struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Fields  []utility.FieldError `json:"fields,omitempty"`
+}
+
+// writeJSON answers the value as JSON with the status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Info("failed to write response", "err", err)
+	}
+}
+
+// writeError answers an error body with the status and the code
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, ErrorBody{Error: ErrorDetails{Code: code, Message: message}})
+}
+
+// writeBookError answers the error of the address book or of the validation of a contact with the matching status
+func writeBookError(w http.ResponseWriter, err error) {
+	var validationErr *utility.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorBody{Error: ErrorDetails{
+			Code:    ValidationFailedCode,
+			Message: "the contact is invalid",
+			Fields:  validationErr.Fields,
+		}})
+	case errors.Is(err, addressbook.ContactNotFound):
+		writeError(w, http.StatusNotFound, NotFoundCode, err.Error())
+	case errors.Is(err, addressbook.ContactAlreadyExists):
+		writeError(w, http.StatusConflict, ConflictCode, err.Error())
+	case errors.Is(err, addressbook.UnknownSortField), errors.Is(err, addressbook.InvalidCursor):
+		writeError(w, http.StatusBadRequest, InvalidRequestCode, err.Error())
+	default:
+		slog.Info("address book request failed", "err", err)
+		writeError(w, http.StatusInternalServerError, InternalErrorCode, "the address book couldn't be updated")
+	}
+}
+
+// methodNotAllowed answers the methods a resource accepts
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, MethodNotAllowedCode, "method not allowed, use "+allowed)
+}
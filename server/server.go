@@ -0,0 +1,103 @@
+// Package server exposes the address book to the other services of the machine as a JSON REST API:
+//
+//	GET    /contacts                     list the contacts, ?sort=&desc=&limit=&cursor= to page through them
+//	GET    /contacts?name=naruto         search by name, ?q= for a full-text search, ?query= for a structured query
+//	POST   /contacts                     add a contact
+//	GET    /contacts/by-phone/{phone}    find the contact with a phone number
+//	GET    /contacts/{id}                read a contact
+//	PUT    /contacts/{id}                replace a contact
+//	DELETE /contacts/{id}                delete a contact
+//
+// Errors are answered with an ErrorBody. The same server answers the contact clients over CardDAV under /carddav/,
+// see the carddav package.
+package server
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/carddav"
+	"GoAddressBook/constants"
+	"GoAddressBook/utility"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/sagikazarmark/slog-shim"
+	"github.com/spf13/viper"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultBind = "127.0.0.1"
+	DefaultPort = 8080
+
+	// maxBodySize limits the size of the contacts sent to the server
+	maxBodySize     = 1 << 20
+	shutdownTimeout = 10 * time.Second
+)
+
+// Server serves the REST API over an address book
+type Server struct {
+	Book      *addressbook.AddressBook
+	Validator *validator.Validate
+}
+
+// NewServer returns a Server over the address book
+func NewServer(book *addressbook.AddressBook) *Server {
+	return &Server{
+		Book:      book,
+		Validator: utility.NewValidator(),
+	}
+}
+
+// Handler routes the requests of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/contacts", s.handleContacts)
+	mux.HandleFunc("/contacts/", s.handleContact)
+	carddav.NewHandler(s.Book).Register(mux)
+	return mux
+}
+
+// Address returns the address the server listens on, from the server.bind and server.port configuration
+func Address() string {
+	bind := viper.GetString(constants.ServerBind)
+	if bind == "" {
+		bind = DefaultBind
+	}
+	port := viper.GetInt(constants.ServerPort)
+	if port == 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(bind, strconv.Itoa(port))
+}
+
+// ListenAndServe serves the API on the configured address until the context is done, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              Address(),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	failed := make(chan error, 1)
+	go func() {
+		slog.Info("address book API listening", "address", httpServer.Addr)
+		failed <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-failed; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
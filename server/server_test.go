@@ -0,0 +1,249 @@
+package server
+
+import (
+	"GoAddressBook/addressbook"
+	"GoAddressBook/models"
+	"GoAddressBook/repository"
+	"GoAddressBook/utility"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer serves a book holding Ada and Alan
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	book := addressbook.NewAddressBook(repository.NewMemoryRepository())
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, contact := range []models.Contact{
+		{ID: "ada", FirstName: "Ada", LastName: "Lovelace", CreatedOn: created,
+			Phones: []models.Phone{{Type: models.MobilePhone, Number: "+919876543210", Primary: true}},
+			Emails: []models.Email{{Type: models.PersonalEmail, Address: "ada@example.com", Primary: true}}},
+		{ID: "alan", FirstName: "Alan", LastName: "Turing", CreatedOn: created.Add(time.Hour),
+			Phones: []models.Phone{{Type: models.WorkPhone, Number: "+919876543211", Primary: true}},
+			Emails: []models.Email{{Type: models.WorkEmail, Address: "alan@example.com", Primary: true}}},
+	} {
+		if _, err := book.AddContact(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(NewServer(book).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends the request and decodes the JSON answered into body, unless the response has no content
+func do(t *testing.T, server *httptest.Server, method, path, requestBody string, body interface{}) *http.Response {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, strings.NewReader(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	resp, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && body != nil {
+		if err = json.NewDecoder(resp.Body).Decode(body); err != nil {
+			t.Fatalf("%s %s: decoding the response: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+// names returns the full names of the contacts
+func names(contacts []models.Contact) []string {
+	result := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		result = append(result, contact.FirstName+" "+contact.LastName)
+	}
+	return result
+}
+
+const grace = `{"first_name": "Grace", "last_name": "Hopper",
+	"phones": [{"type": "mobile", "number": "+91 98765 43212"}], "emails": [{"type": "work", "address": "grace@example.com"}]}`
+
+func TestContacts(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   []string // names of the contacts listed or answered
+	}{
+		{name: "list", method: http.MethodGet, path: "/contacts", status: http.StatusOK, want: []string{"Ada Lovelace", "Alan Turing"}},
+		{name: "list by creation date", method: http.MethodGet, path: "/contacts?sort=created_on&desc=true", status: http.StatusOK,
+			want: []string{"Alan Turing", "Ada Lovelace"}},
+		{name: "search by name", method: http.MethodGet, path: "/contacts?name=lovelace", status: http.StatusOK, want: []string{"Ada Lovelace"}},
+		{name: "full-text search", method: http.MethodGet, path: "/contacts?q=email:alan", status: http.StatusOK, want: []string{"Alan Turing"}},
+		{name: "structured query", method: http.MethodGet, path: "/contacts?query=first_name+%3D+%22ada%22", status: http.StatusOK,
+			want: []string{"Ada Lovelace"}},
+		{name: "search without match", method: http.MethodGet, path: "/contacts?name=hopper", status: http.StatusOK, want: []string{}},
+		{name: "get", method: http.MethodGet, path: "/contacts/alan", status: http.StatusOK, want: []string{"Alan Turing"}},
+		{name: "get by phone", method: http.MethodGet, path: "/contacts/by-phone/+91%2098765%2043210", status: http.StatusOK,
+			want: []string{"Ada Lovelace"}},
+		{name: "create", method: http.MethodPost, path: "/contacts", body: grace, status: http.StatusCreated, want: []string{"Grace Hopper"}},
+		{name: "update", method: http.MethodPut, path: "/contacts/ada", status: http.StatusOK, want: []string{"Ada King"},
+			body: `{"first_name": "Ada", "last_name": "King", "phones": [{"number": "+919876543210"}], "emails": [{"address": "ada@example.com"}]}`},
+		{name: "delete", method: http.MethodDelete, path: "/contacts/ada", status: http.StatusNoContent},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			var body json.RawMessage
+			resp := do(t, server, test.method, test.path, test.body, &body)
+			if resp.StatusCode != test.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, test.status, body)
+			}
+			if test.want == nil {
+				return
+			}
+			var got []string
+			if strings.HasPrefix(test.path, "/contacts?") || test.path == "/contacts" && test.method == http.MethodGet {
+				var contacts ContactsBody
+				if err := json.Unmarshal(body, &contacts); err != nil {
+					t.Fatal(err)
+				}
+				got = names(contacts.Contacts)
+			} else {
+				var contact models.Contact
+				if err := json.Unmarshal(body, &contact); err != nil {
+					t.Fatal(err)
+				}
+				got = names([]models.Contact{contact})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("contacts = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestContactLifecycle(t *testing.T) {
+	server := newTestServer(t)
+
+	var created models.Contact
+	resp := do(t, server, http.MethodPost, "/contacts", strings.Replace(grace, "{", `{"id": "../ada",`, 1), &created)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d", resp.StatusCode)
+	}
+	if created.ID == "" || created.ID == "../ada" || resp.Header.Get("Location") != "/contacts/"+created.ID {
+		t.Fatalf("created %q at %q, want a generated ID", created.ID, resp.Header.Get("Location"))
+	}
+	if created.Phones[0].Number != "+919876543212" || !created.Phones[0].Primary {
+		t.Errorf("phone = %+v, want it normalized and primary", created.Phones[0])
+	}
+
+	var read models.Contact
+	if resp = do(t, server, http.MethodGet, "/contacts/"+created.ID, "", &read); resp.StatusCode != http.StatusOK {
+		t.Fatalf("get status = %d", resp.StatusCode)
+	}
+	if !reflect.DeepEqual(read, created) {
+		t.Errorf("read %+v\nwant %+v", read, created)
+	}
+
+	var page ContactsBody
+	if resp = do(t, server, http.MethodGet, "/contacts?limit=2", "", &page); resp.StatusCode != http.StatusOK {
+		t.Fatalf("list status = %d", resp.StatusCode)
+	}
+	if len(page.Contacts) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page = %q, next cursor %q", names(page.Contacts), page.NextCursor)
+	}
+	var next ContactsBody
+	do(t, server, http.MethodGet, "/contacts?limit=2&cursor="+url.QueryEscape(page.NextCursor), "", &next)
+	if got := names(next.Contacts); len(got) != 1 || got[0] != "Grace Hopper" || next.NextCursor != "" {
+		t.Errorf("last page = %q, next cursor %q", got, next.NextCursor)
+	}
+
+	if resp = do(t, server, http.MethodDelete, "/contacts/"+created.ID, "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d", resp.StatusCode)
+	}
+	if resp = do(t, server, http.MethodGet, "/contacts/"+created.ID, "", &ErrorBody{}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{name: "negative limit", method: http.MethodGet, path: "/contacts?limit=-1", status: http.StatusBadRequest, code: InvalidRequestCode},
+		{name: "unknown sort field", method: http.MethodGet, path: "/contacts?sort=age", status: http.StatusBadRequest, code: InvalidRequestCode},
+		{name: "invalid cursor", method: http.MethodGet, path: "/contacts?limit=1&cursor=nope", status: http.StatusBadRequest, code: InvalidRequestCode},
+		{name: "invalid query", method: http.MethodGet, path: "/contacts?query=city+%3D", status: http.StatusBadRequest, code: InvalidRequestCode},
+		{name: "invalid JSON", method: http.MethodPost, path: "/contacts", body: `{"first_name": `, status: http.StatusBadRequest, code: InvalidRequestCode},
+		{name: "unknown contact", method: http.MethodGet, path: "/contacts/grace", status: http.StatusNotFound, code: NotFoundCode},
+		{name: "unknown phone", method: http.MethodGet, path: "/contacts/by-phone/+919876543212", status: http.StatusNotFound, code: NotFoundCode},
+		{name: "update unknown contact", method: http.MethodPut, path: "/contacts/grace", body: grace, status: http.StatusNotFound, code: NotFoundCode},
+		{name: "delete unknown contact", method: http.MethodDelete, path: "/contacts/grace", status: http.StatusNotFound, code: NotFoundCode},
+		{name: "nested path", method: http.MethodGet, path: "/contacts/ada/phones", status: http.StatusNotFound, code: NotFoundCode},
+		{name: "create with a taken phone", method: http.MethodPost, path: "/contacts", status: http.StatusConflict, code: ConflictCode,
+			body: strings.Replace(grace, "+91 98765 43212", "+919876543211", 1)},
+		{name: "update with a taken phone", method: http.MethodPut, path: "/contacts/ada", status: http.StatusConflict, code: ConflictCode,
+			body: strings.Replace(grace, "+91 98765 43212", "+919876543211", 1)},
+		{name: "create invalid contact", method: http.MethodPost, path: "/contacts", body: `{"first_name": "Grace"}`,
+			status: http.StatusUnprocessableEntity, code: ValidationFailedCode},
+		{name: "method not allowed", method: http.MethodPatch, path: "/contacts/ada", status: http.StatusMethodNotAllowed, code: MethodNotAllowedCode},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			var body ErrorBody
+			resp := do(t, server, test.method, test.path, test.body, &body)
+			if resp.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, test.status)
+			}
+			if body.Error.Code != test.code || body.Error.Message == "" {
+				t.Errorf("error = %+v, want code %q and a message", body.Error, test.code)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %q", contentType)
+			}
+		})
+	}
+}
+
+func TestValidationErrorBody(t *testing.T) {
+	server := newTestServer(t)
+	contact := `{"first_name": "Grace", "last_name": "Hopper",
+		"phones": [{"type": "cell", "number": "12"}], "emails": [{"type": "work", "address": "grace"}],
+		"addresses": [{"city": "Bengaluru", "zip": "5600", "country": "India"}]}`
+	var body ErrorBody
+	resp := do(t, server, http.MethodPost, "/contacts", contact, &body)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+	want := []utility.FieldError{
+		{Field: "phones[0].type", Code: utility.OneOfCode, Value: "cell", Param: "mobile work home"},
+		{Field: "phones[0].number", Code: utility.PhoneNumberFormatCode, Value: "12"},
+		{Field: "emails[0].address", Code: utility.EmailFormatCode, Value: "grace"},
+		{Field: "addresses[0].zip", Code: utility.PostalCodeFormatCode, Value: "5600", Param: "India"},
+	}
+	if body.Error.Code != ValidationFailedCode {
+		t.Errorf("code = %q, want %q", body.Error.Code, ValidationFailedCode)
+	}
+	got := make([]utility.FieldError, 0, len(body.Error.Fields))
+	for _, field := range body.Error.Fields {
+		if field.Message == "" {
+			t.Errorf("field %s has no message", field.Field)
+		}
+		field.Message = ""
+		got = append(got, field)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %+v\nwant %+v", got, want)
+	}
+}
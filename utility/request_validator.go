@@ -2,8 +2,12 @@ package utility
 
 import (
 	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"GoAddressBook/phonenumber"
+	"GoAddressBook/postalcode"
 	"github.com/go-playground/validator/v10"
 	"github.com/sagikazarmark/slog-shim"
+	"github.com/spf13/viper"
 	"reflect"
 	"regexp"
 	"strings"
@@ -46,11 +50,7 @@ func NewValidator() *validator.Validate {
 		slog.Info("Error while registering custom validator func PhoneNumberFormatValidator %s\n", err.Error())
 		return nil
 	}
-	err = requestValidator.RegisterValidation("pinCodeFormat", PinCodeFormatValidator)
-	if err != nil {
-		slog.Info("Error while registering custom validator func PinCodeFormatValidator %s\n", err.Error())
-		return nil
-	}
+	requestValidator.RegisterStructValidation(AddressStructLevelValidator, models.Address{})
 
 	return requestValidator
 }
@@ -99,16 +99,28 @@ func IsValidEmailAddress(emailAddress string) bool {
 	return true
 }
 
+// PhoneNumberFormatValidator accepts numbers in any format that are valid in their country,
+// numbers without country code being read in the configured default region
 func PhoneNumberFormatValidator(fl validator.FieldLevel) bool {
 	fieldValue := fl.Field().String()
-	reg := regexp.MustCompile(constants.PhoneNumberRegex)
-	phoneNumber := reg.FindStringSubmatch(fieldValue)
-	return phoneNumber != nil
+	return phonenumber.IsValid(fieldValue, viper.GetString(constants.PhoneDefaultRegion))
 }
 
-func PinCodeFormatValidator(fl validator.FieldLevel) bool {
-	fieldValue := fl.Field().String()
-	re := regexp.MustCompile(constants.PinCodeRegex)
-	pincodeValue := re.FindStringSubmatch(fieldValue)
-	return pincodeValue != nil && !strings.Contains(pincodeValue[0][3:], "000")
+// AddressStructLevelValidator checks the postal code against the format of the country of the address,
+// addresses without country being in the configured default country
+func AddressStructLevelValidator(sl validator.StructLevel) {
+	address := sl.Current().Interface().(models.Address)
+	if address.Zip == "" {
+		return
+	}
+	country := address.Country
+	if strings.TrimSpace(country) == "" {
+		country = viper.GetString(constants.AddressDefaultCountry)
+	}
+	if country == "" {
+		country = postalcode.DefaultCountry
+	}
+	if !postalcode.IsValid(country, address.Zip) {
+		sl.ReportError(address.Zip, "zip", "Zip", "postalCodeFormat", country)
+	}
 }
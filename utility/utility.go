@@ -5,24 +5,64 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"strings"
 )
 
+// RequestBodyValidator checks the fields every contact needs, returning a *ValidationError listing the missing ones
 func RequestBodyValidator(contact models.Contact) error {
-	if len(contact.FirstName) == 0 || len(contact.LastName) == 0 || len(contact.PhoneNumber) == 0 || len(contact.EmailAddress) == 0 {
-		return errors.New("given inputs are invalid")
+	var missing []FieldError
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"first_name", len(contact.FirstName) == 0},
+		{"last_name", len(contact.LastName) == 0},
+		{"phones", len(contact.Phones) == 0},
+		{"emails", len(contact.Emails) == 0},
+	} {
+		if field.missing {
+			missing = append(missing, newFieldError(field.name, RequiredCode, "", ""))
+		}
+	}
+	if len(missing) > 0 {
+		return &ValidationError{Fields: missing}
 	}
 	return nil
 }
 
+// ParseValidatorErrMessage converts the errors of the validator into a *ValidationError listing every invalid field
 func ParseValidatorErrMessage(err error) error {
-	description := "Invalid request"
 	var fieldErrors validator.ValidationErrors
-	if errors.As(err, &fieldErrors) {
-		err := fieldErrors[0]
-		description = fmt.Sprintf("Invalid %s provided: %s", err.Field(), err.Value())
+	if !errors.As(err, &fieldErrors) {
+		return errors.New("Invalid request")
 	}
-	return errors.New(description)
+	validationErr := &ValidationError{}
+	for _, fieldErr := range fieldErrors {
+		// The namespace starts with the name of the validated struct, e.g. Contact.phones[0].number
+		field := fieldErr.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		validationErr.Fields = append(validationErr.Fields,
+			newFieldError(field, fieldErr.Tag(), fmt.Sprint(fieldErr.Value()), fieldErr.Param()))
+	}
+	return validationErr
+}
+
+// NewContactID generates a random, immutable identifier for a contact
+func NewContactID() string {
+	return uuid.NewString()
+}
+
+// CleanPhoneNumber removes the spaces, dashes and brackets people put in phone numbers, keeping the leading +
+func CleanPhoneNumber(number string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '+' {
+			return r
+		}
+		return -1
+	}, number)
 }
 
 func GetFirstMiddleAndLastNamesFromFullName(fullName string) (string, string, string) {
@@ -0,0 +1,106 @@
+package utility
+
+import (
+	"GoAddressBook/constants"
+	"GoAddressBook/models"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+// Codes of the validation rules, as reported in FieldError.Code
+const (
+	RequiredCode          = "required"
+	FirstNameFormatCode   = "firstNameFormat"
+	LastNameFormatCode    = "lastNameFormat"
+	EmailFormatCode       = "emailFormat"
+	PhoneNumberFormatCode = "phoneNumberFormat"
+	OneOfCode             = "oneof"
+	PostalCodeFormatCode  = "postalCodeFormat"
+)
+
+// validationMessages maps the rule codes to their i18n message, rules not listed using constants.ValidationInvalid
+var validationMessages = map[string]string{
+	RequiredCode:          constants.ValidationRequired,
+	FirstNameFormatCode:   constants.ValidationFirstNameFormat,
+	LastNameFormatCode:    constants.ValidationLastNameFormat,
+	EmailFormatCode:       constants.ValidationEmailFormat,
+	PhoneNumberFormatCode: constants.ValidationPhoneNumberFormat,
+	OneOfCode:             constants.ValidationOneOf,
+	PostalCodeFormatCode:  constants.ValidationPostalCodeFormat,
+}
+
+// Translator localizes a message, as i18n.Internationalization does
+type Translator interface {
+	T(key string, params map[string]interface{}) (string, error)
+}
+
+// FieldError tells why a field of a contact is invalid
+type FieldError struct {
+	Field   string `json:"field"` // path of the field, e.g. phones[0].number
+	Code    string `json:"code"`  // the rule that failed, e.g. phoneNumberFormat
+	Value   string `json:"value,omitempty"`
+	Param   string `json:"param,omitempty"` // parameter of the rule, e.g. the allowed values of oneof
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a contact
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize translates the message of every field error, keeping the English one when there is no translation
+func (e *ValidationError) Localize(translator Translator) {
+	for i, field := range e.Fields {
+		key, found := validationMessages[field.Code]
+		if !found {
+			key = constants.ValidationInvalid
+		}
+		message, err := translator.T(key, map[string]interface{}{
+			"Field": field.Field,
+			"Value": field.Value,
+			"Param": field.Param,
+		})
+		if err == nil {
+			e.Fields[i].Message = message
+		}
+	}
+}
+
+// ValidateContact checks the required fields and the format of every field of the contact,
+// returning a *ValidationError listing all the invalid ones
+func ValidateContact(validate *validator.Validate, contact models.Contact) error {
+	validationErr := &ValidationError{}
+	if err := RequestBodyValidator(contact); err != nil && !errors.As(err, &validationErr) {
+		return err
+	}
+	if structErr := validate.Struct(contact); structErr != nil {
+		var formatErr *ValidationError
+		if !errors.As(ParseValidatorErrMessage(structErr), &formatErr) {
+			return structErr
+		}
+		validationErr.Fields = append(validationErr.Fields, formatErr.Fields...)
+	}
+	if len(validationErr.Fields) == 0 {
+		return nil
+	}
+	return validationErr
+}
+
+// newFieldError builds a field error with its English message
+func newFieldError(field, code, value, param string) FieldError {
+	message := fmt.Sprintf("Invalid %s provided: %s", field, value)
+	if code == RequiredCode {
+		message = fmt.Sprintf("%s is required", field)
+	}
+	return FieldError{Field: field, Code: code, Value: value, Param: param, Message: message}
+}
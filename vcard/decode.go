@@ -0,0 +1,268 @@
+package vcard
+
+import (
+	"GoAddressBook/models"
+	"GoAddressBook/utility"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property is a content line of a vCard, such as TEL;TYPE=cell:+919876543210
+type property struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// Decode reads every vCard of the stream, such as a multi-card .vcf file, into contacts.
+// The contacts are not validated and have no ID, the book generating one when they're added, the UID of the card
+// being kept in their UID field. REV is read as the last modification time.
+func Decode(r io.Reader) ([]models.Contact, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []models.Contact
+	var card []property
+	inCard := false
+	for number, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			if inCard {
+				return nil, fmt.Errorf("line %d: vCard started before the previous one ended", number+1)
+			}
+			inCard, card = true, nil
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			if !inCard {
+				return nil, fmt.Errorf("line %d: vCard ended before it started", number+1)
+			}
+			contact, err := toContact(card)
+			if err != nil {
+				return nil, fmt.Errorf("vCard %d: %w", len(contacts)+1, err)
+			}
+			contacts = append(contacts, contact)
+			inCard = false
+		case inCard:
+			card = append(card, prop)
+		default:
+			return nil, fmt.Errorf("line %d: property %s outside of a vCard", number+1, prop.name)
+		}
+	}
+	if inCard {
+		return nil, fmt.Errorf("vCard %d is not ended", len(contacts)+1)
+	}
+	return contacts, nil
+}
+
+// unfold joins the lines continued on the next one by a leading space or tab
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line into its name, parameters and value, dropping the group prefix
+func parseLine(line string) (property, error) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := splitUnquoted(line[:colon], ';')
+	name := parts[0]
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	prop := property{name: strings.ToUpper(name), params: map[string][]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			// vCard 2.1 lists types without the TYPE= prefix
+			key, value = "TYPE", param
+		}
+		key = strings.ToUpper(key)
+		for _, v := range strings.Split(strings.Trim(value, `"`), ",") {
+			prop.params[key] = append(prop.params[key], strings.ToLower(v))
+		}
+	}
+	return prop, nil
+}
+
+func splitUnquoted(s string, separator rune) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == separator && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// components splits a structured value such as N or ADR on its unescaped semicolons
+func components(value string) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			current.WriteByte(value[i])
+			current.WriteByte(value[i+1])
+			i++
+		case value[i] == ';':
+			parts = append(parts, unescape(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	return append(parts, unescape(current.String()))
+}
+
+func unescape(value string) string {
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				result.WriteByte('\n')
+			default:
+				result.WriteByte(value[i])
+			}
+			continue
+		}
+		result.WriteByte(value[i])
+	}
+	return result.String()
+}
+
+func component(parts []string, index int) string {
+	if index < len(parts) {
+		return strings.TrimSpace(parts[index])
+	}
+	return ""
+}
+
+// isPreferred tells whether the property is flagged as preferred, by TYPE=pref in 3.0 or PREF=1 in 4.0
+func (p property) isPreferred() bool {
+	for _, value := range p.params["TYPE"] {
+		if value == "pref" {
+			return true
+		}
+	}
+	preference, err := strconv.Atoi(strings.Join(p.params["PREF"], ""))
+	return err == nil && preference == 1
+}
+
+func toContact(card []property) (models.Contact, error) {
+	var contact models.Contact
+	var fullName string
+	for _, prop := range card {
+		switch prop.name {
+		case "N":
+			parts := components(prop.value)
+			contact.LastName = component(parts, 0)
+			contact.FirstName = component(parts, 1)
+		case "FN":
+			fullName = unescape(prop.value)
+		case "TEL":
+			contact.Phones = append(contact.Phones, models.Phone{
+				Type:    bookType(phoneTypes, prop.params["TYPE"], models.MobilePhone),
+				Number:  utility.CleanPhoneNumber(strings.TrimPrefix(unescape(prop.value), "tel:")),
+				Primary: prop.isPreferred(),
+			})
+		case "EMAIL":
+			contact.Emails = append(contact.Emails, models.Email{
+				Type:    bookType(emailTypes, prop.params["TYPE"], models.PersonalEmail),
+				Address: strings.TrimSpace(unescape(prop.value)),
+				Primary: prop.isPreferred(),
+			})
+		case "ADR":
+			parts := components(prop.value)
+			contact.Addresses = append(contact.Addresses, models.Address{
+				Type:    bookType(addressTypes, prop.params["TYPE"], models.PersonalAddress),
+				Street:  strings.TrimSpace(strings.Join(nonEmpty(component(parts, 1), component(parts, 2)), ", ")),
+				City:    component(parts, 3),
+				State:   component(parts, 4),
+				Zip:     component(parts, 5),
+				Country: component(parts, 6),
+				Primary: prop.isPreferred(),
+			})
+		case "UID":
+			contact.UID = strings.TrimSpace(unescape(prop.value))
+		case "REV":
+			revision, err := parseRevision(prop.value)
+			if err != nil {
+				return contact, fmt.Errorf("invalid REV %q", prop.value)
+			}
+			contact.ModifiedOn = revision
+		}
+	}
+
+	if contact.FirstName == "" && contact.LastName == "" {
+		contact.FirstName, _, contact.LastName = utility.GetFirstMiddleAndLastNamesFromFullName(fullName)
+	}
+	if contact.CreatedOn.IsZero() {
+		contact.CreatedOn = time.Now()
+	}
+	contact.NormalizePrimary()
+	return contact, nil
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// parseRevision reads REV in the basic or extended ISO 8601 forms, with or without time
+func parseRevision(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	var err error
+	for _, layout := range []string{revisionFormat, "20060102T150405Z0700", time.RFC3339, "20060102", time.DateOnly} {
+		var revision time.Time
+		if revision, err = time.Parse(layout, value); err == nil {
+			return revision, nil
+		}
+	}
+	return time.Time{}, err
+}
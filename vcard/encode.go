@@ -0,0 +1,115 @@
+package vcard
+
+import (
+	"GoAddressBook/models"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineLength is the number of octets after which lines are folded
+const maxLineLength = 75
+
+// Encode writes the contacts as vCards of the given version, one after the other
+func Encode(w io.Writer, contacts []models.Contact, version string) error {
+	if version != Version3 && version != Version4 {
+		return fmt.Errorf("unsupported vCard version %q", version)
+	}
+	for _, contact := range contacts {
+		for _, line := range contactLines(contact, version) {
+			if _, err := io.WriteString(w, fold(line)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func contactLines(contact models.Contact, version string) []string {
+	lines := []string{"BEGIN:VCARD", "VERSION:" + version}
+	if uid := UID(contact); uid != "" {
+		lines = append(lines, "UID:"+escape(uid))
+	}
+	lines = append(lines,
+		"N:"+escape(contact.LastName)+";"+escape(contact.FirstName)+";;;",
+		"FN:"+escape(strings.TrimSpace(contact.FirstName+" "+contact.LastName)),
+	)
+
+	for _, phone := range contact.Phones {
+		types := []string{vcardType(phoneTypes, phone.Type), "voice"}
+		if version == Version4 {
+			lines = append(lines, "TEL;VALUE=uri"+typeParams(version, types, phone.Primary)+":tel:"+phone.Number)
+		} else {
+			lines = append(lines, "TEL"+typeParams(version, types, phone.Primary)+":"+escape(phone.Number))
+		}
+	}
+	for _, email := range contact.Emails {
+		types := []string{vcardType(emailTypes, email.Type)}
+		if version == Version3 {
+			types = append([]string{"internet"}, types...)
+		}
+		lines = append(lines, "EMAIL"+typeParams(version, types, email.Primary)+":"+escape(email.Address))
+	}
+	for _, address := range contact.Addresses {
+		value := strings.Join([]string{
+			"", "",
+			escape(address.Street),
+			escape(address.City),
+			escape(address.State),
+			escape(address.Zip),
+			escape(address.Country),
+		}, ";")
+		lines = append(lines, "ADR"+typeParams(version, []string{vcardType(addressTypes, address.Type)}, address.Primary)+":"+value)
+	}
+	if !contact.ModifiedOn.IsZero() {
+		lines = append(lines, "REV:"+contact.ModifiedOn.UTC().Format(revisionFormat))
+	}
+	return append(lines, "END:VCARD")
+}
+
+// typeParams writes the TYPE parameter and the preference, as a type in 3.0 and as PREF in 4.0
+func typeParams(version string, types []string, preferred bool) string {
+	types = nonEmpty(types...)
+	if preferred && version == Version3 {
+		types = append(types, "pref")
+	}
+	var params string
+	if len(types) > 0 {
+		if version == Version3 {
+			params = ";TYPE=" + strings.ToUpper(strings.Join(types, ","))
+		} else {
+			params = ";TYPE=" + strings.Join(types, ",")
+			if len(types) > 1 {
+				params = `;TYPE="` + strings.Join(types, ",") + `"`
+			}
+		}
+	}
+	if preferred && version == Version4 {
+		params += ";PREF=1"
+	}
+	return params
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(value)
+}
+
+// fold ends the line with CRLF, splitting it every 75 octets without breaking a UTF-8 sequence
+func fold(line string) string {
+	var folded strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineLength - 1
+	}
+	folded.WriteString(line)
+	folded.WriteString("\r\n")
+	return folded.String()
+}
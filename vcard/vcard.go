@@ -0,0 +1,77 @@
+// Package vcard reads and writes contacts as vCards (RFC 2426 for version 3.0, RFC 6350 for version 4.0)
+package vcard
+
+import (
+	"GoAddressBook/models"
+	"github.com/google/uuid"
+	"strings"
+)
+
+const (
+	Version3 = "3.0"
+	Version4 = "4.0"
+
+	// uuidURN prefixes the UIDs holding a UUID
+	uuidURN = "urn:uuid:"
+
+	// revisionFormat is the basic ISO 8601 form REV is written in by both versions
+	revisionFormat = "20060102T150405Z"
+)
+
+// Versions lists the vCard versions contacts can be exported to
+var Versions = []string{Version3, Version4}
+
+// phoneTypes maps the TYPE parameter of TEL to the phone types of the book, in order of preference
+var phoneTypes = []struct{ vcard, book string }{
+	{"cell", models.MobilePhone},
+	{"work", models.WorkPhone},
+	{"home", models.HomePhone},
+}
+
+// emailTypes maps the TYPE parameter of EMAIL to the email types of the book
+var emailTypes = []struct{ vcard, book string }{
+	{"work", models.WorkEmail},
+	{"home", models.PersonalEmail},
+}
+
+// addressTypes maps the TYPE parameter of ADR to the address types of the book, billing being an extension
+var addressTypes = []struct{ vcard, book string }{
+	{"work", models.ProfessionalAddress},
+	{"x-billing", models.BillingAddress},
+	{"billing", models.BillingAddress},
+	{"home", models.PersonalAddress},
+}
+
+// bookType returns the book type for the first vCard type found, or the fallback
+func bookType(mapping []struct{ vcard, book string }, types []string, fallback string) string {
+	for _, entry := range mapping {
+		for _, value := range types {
+			if strings.EqualFold(value, entry.vcard) {
+				return entry.book
+			}
+		}
+	}
+	return fallback
+}
+
+// vcardType returns the vCard type for a book type
+func vcardType(mapping []struct{ vcard, book string }, value string) string {
+	for _, entry := range mapping {
+		if entry.book == value {
+			return entry.vcard
+		}
+	}
+	return ""
+}
+
+// UID returns the UID of the contact: the one of the card it came from, or else a urn:uuid URI when its ID is a UUID
+// and the ID itself otherwise
+func UID(contact models.Contact) string {
+	if contact.UID != "" {
+		return contact.UID
+	}
+	if _, err := uuid.Parse(contact.ID); err == nil && len(contact.ID) == 36 {
+		return uuidURN + contact.ID
+	}
+	return contact.ID
+}
@@ -0,0 +1,154 @@
+package vcard
+
+import (
+	"GoAddressBook/models"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+		want models.Contact
+	}{
+		{
+			name: "uuid uid",
+			card: "BEGIN:VCARD\r\nVERSION:4.0\r\nUID:urn:uuid:0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11\r\nN:Lovelace;Ada;;;\r\nEND:VCARD\r\n",
+			want: models.Contact{UID: "urn:uuid:0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11", FirstName: "Ada", LastName: "Lovelace"},
+		},
+		{
+			name: "opaque uid",
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:ada@example.com\r\nFN:Ada Lovelace\r\nEND:VCARD\r\n",
+			want: models.Contact{UID: "ada@example.com", FirstName: "Ada", LastName: "Lovelace"},
+		},
+		{
+			name: "rev is the modification date",
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Lovelace;Ada;;;\r\nREV:19951031T222710Z\r\nEND:VCARD\r\n",
+			want: models.Contact{FirstName: "Ada", LastName: "Lovelace", ModifiedOn: time.Date(1995, 10, 31, 22, 27, 10, 0, time.UTC)},
+		},
+		{
+			name: "extended rev with an offset",
+			card: "BEGIN:VCARD\r\nVERSION:4.0\r\nN:Lovelace;Ada;;;\r\nREV:1995-10-31T23:27:10+01:00\r\nEND:VCARD\r\n",
+			want: models.Contact{FirstName: "Ada", LastName: "Lovelace", ModifiedOn: time.Date(1995, 10, 31, 22, 27, 10, 0, time.UTC)},
+		},
+		{
+			name: "folded lines, types and preference",
+			card: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Lovelace;Ada;;;\r\nTEL;TYPE=WORK,VOICE:+14155550100\r\nTEL;TYPE=CELL,PREF:+1415\r\n 5550101\r\n" +
+				"item1.EMAIL;TYPE=INTERNET,WORK:ada@example.com\r\nADR;TYPE=HOME:;;12 St James\\, Square;London;;SW1Y 4JH;UK\r\nEND:VCARD\r\n",
+			want: models.Contact{FirstName: "Ada", LastName: "Lovelace",
+				Phones: []models.Phone{
+					{Type: models.WorkPhone, Number: "+14155550100"},
+					{Type: models.MobilePhone, Number: "+14155550101", Primary: true},
+				},
+				Emails:    []models.Email{{Type: models.WorkEmail, Address: "ada@example.com", Primary: true}},
+				Addresses: []models.Address{{Type: models.PersonalAddress, Street: "12 St James, Square", City: "London", Zip: "SW1Y 4JH", Country: "UK", Primary: true}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contacts, err := Decode(strings.NewReader(test.card))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(contacts) != 1 {
+				t.Fatalf("decoded %d contacts, want 1", len(contacts))
+			}
+			got := contacts[0]
+			if got.CreatedOn.Year() == 1995 {
+				t.Errorf("CreatedOn taken from REV: %v", got.CreatedOn)
+			}
+			got.CreatedOn = test.want.CreatedOn
+			if got.ModifiedOn.Equal(test.want.ModifiedOn) {
+				got.ModifiedOn = test.want.ModifiedOn
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v\nwant %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+	}{
+		{name: "not ended", card: "BEGIN:VCARD\r\nN:Lovelace;Ada;;;\r\n"},
+		{name: "nested", card: "BEGIN:VCARD\r\nBEGIN:VCARD\r\nEND:VCARD\r\n"},
+		{name: "outside of a card", card: "N:Lovelace;Ada;;;\r\n"},
+		{name: "missing colon", card: "BEGIN:VCARD\r\nN\r\nEND:VCARD\r\n"},
+		{name: "invalid rev", card: "BEGIN:VCARD\r\nN:Lovelace;Ada;;;\r\nREV:yesterday\r\nEND:VCARD\r\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(test.card)); err == nil {
+				t.Error("Decode succeeded")
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	contact := models.Contact{ID: "0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11", FirstName: "Ada", LastName: "Lovelace",
+		ModifiedOn: time.Date(2024, 3, 5, 18, 4, 9, 0, time.FixedZone("CET", 3600)),
+		Phones:     []models.Phone{{Type: models.WorkPhone, Number: "+14155550100", Primary: true}, {Type: models.HomePhone, Number: "+14155550101"}},
+		Emails:     []models.Email{{Type: models.PersonalEmail, Address: "ada@example.com", Primary: true}},
+		Addresses:  []models.Address{{Type: models.BillingAddress, Street: "12 St James; Square", City: "London", Country: "UK", Primary: true}},
+	}
+	// The decoded contact gets its ID from the book, the card only carrying its UID
+	want := contact
+	want.ID = ""
+	want.UID = "urn:uuid:" + contact.ID
+	for _, version := range Versions {
+		t.Run(version, func(t *testing.T) {
+			var card bytes.Buffer
+			if err := Encode(&card, []models.Contact{contact}, version); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(card.String(), "REV:20240305T170409Z\r\n") {
+				t.Errorf("card doesn't carry the modification date in UTC:\n%s", card.String())
+			}
+			contacts, err := Decode(&card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := contacts[0]
+			got.CreatedOn = contact.CreatedOn
+			if !got.ModifiedOn.Equal(contact.ModifiedOn) {
+				t.Errorf("ModifiedOn = %v, want %v", got.ModifiedOn, contact.ModifiedOn)
+			}
+			got.ModifiedOn = contact.ModifiedOn
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUID(t *testing.T) {
+	tests := []struct {
+		contact models.Contact
+		uid     string
+	}{
+		{contact: models.Contact{ID: "0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11"}, uid: "urn:uuid:0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11"},
+		{contact: models.Contact{ID: "ada"}, uid: "ada"},
+		{contact: models.Contact{ID: "0b7e6c3a3c2e4c579a535f2b0c8f1e11"}, uid: "0b7e6c3a3c2e4c579a535f2b0c8f1e11"},
+		{contact: models.Contact{ID: "0b7e6c3a-3c2e-4c57-9a53-5f2b0c8f1e11", UID: "ada@example.com"}, uid: "ada@example.com"},
+	}
+	for _, test := range tests {
+		if uid := UID(test.contact); uid != test.uid {
+			t.Errorf("UID(%+v) = %q, want %q", test.contact, uid, test.uid)
+		}
+	}
+}
+
+func TestEncodeUnsupportedVersion(t *testing.T) {
+	if err := Encode(&bytes.Buffer{}, nil, "2.1"); err == nil {
+		t.Error("Encode to vCard 2.1 succeeded")
+	}
+}